@@ -19,10 +19,13 @@ package account
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/deboard"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/orgs"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/quota"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/roles"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/status"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/tokenurl"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/transfer"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users"
 )
 
@@ -40,4 +43,7 @@ func init() {
 	Cmd.AddCommand(status.Cmd)
 	Cmd.AddCommand(roles.Cmd)
 	Cmd.AddCommand(users.Cmd)
+	Cmd.AddCommand(transfer.Cmd)
+	Cmd.AddCommand(tokenurl.Cmd)
+	Cmd.AddCommand(deboard.Cmd)
 }
@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "deboard USERNAME",
+	Short: "Show what an account owns before deboarding it",
+	Long: "Show the subscriptions, clusters and roles of an account, so that the impact of " +
+		"removing it can be reviewed. The account can be given by user name, or 'me' for " +
+		"the current account. Nothing is modified.",
+	Example: " ocm account deboard jdoe\n" +
+		" ocm account deboard jdoe --output json",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+// report contains everything that is owned by an account.
+type report struct {
+	Account       reportAccount        `json:"account"`
+	Subscriptions []reportSubscription `json:"subscriptions"`
+	Clusters      []reportCluster      `json:"clusters"`
+	Roles         []string             `json:"roles"`
+}
+
+type reportAccount struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+}
+
+type reportSubscription struct {
+	ID          string `json:"id"`
+	ClusterID   string `json:"cluster_id,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+type reportCluster struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create the connection, and remember to close it:
+	connection, err := ocm.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer config.Close(connection)
+
+	// Find the account:
+	accountID, err := acc_util.GetAccountID(argv[0], connection)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := config.RequestContext(connection)
+	accountResponse, err := connection.AccountsMgmt().V1().Accounts().Account(accountID).Get().
+		SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve account '%s': %v", accountID, err)
+	}
+	account := accountResponse.Body()
+	result := &report{
+		Account: reportAccount{
+			ID:       account.ID(),
+			Username: account.Username(),
+			Email:    account.Email(),
+		},
+		Subscriptions: []reportSubscription{},
+		Clusters:      []reportCluster{},
+		Roles:         []string{},
+	}
+
+	// Retrieve the subscriptions and the clusters, and the roles, at the same time:
+	var wait sync.WaitGroup
+	var subsErr, rolesErr error
+	wait.Add(2)
+	go func() {
+		defer wait.Done()
+		var subscriptions []*amv1.Subscription
+		subscriptions, subsErr = acc_util.GetSubscriptionsFromCreator(account.ID(), connection)
+		if subsErr != nil {
+			return
+		}
+		var clusterIDs []string
+		for _, sub := range subscriptions {
+			result.Subscriptions = append(result.Subscriptions, reportSubscription{
+				ID:          sub.ID(),
+				ClusterID:   sub.ClusterID(),
+				DisplayName: sub.DisplayName(),
+			})
+			if sub.ClusterID() != "" {
+				clusterIDs = append(clusterIDs, fmt.Sprintf("'%s'", sub.ClusterID()))
+			}
+		}
+		if len(clusterIDs) == 0 {
+			return
+		}
+		var clusters []*cmv1.Cluster
+		clusters, subsErr = getClusters(connection, clusterIDs)
+		for _, cluster := range clusters {
+			result.Clusters = append(result.Clusters, reportCluster{
+				ID:    cluster.ID(),
+				Name:  cluster.Name(),
+				State: string(cluster.State()),
+			})
+		}
+	}()
+	go func() {
+		defer wait.Done()
+		var roles []string
+		roles, rolesErr = acc_util.GetRolesFromUser(account, connection)
+		if roles != nil {
+			result.Roles = roles
+		}
+	}()
+	wait.Wait()
+	if subsErr != nil {
+		return subsErr
+	}
+	if rolesErr != nil {
+		return rolesErr
+	}
+
+	// Print the report:
+	if output.JSON() {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+		if err != nil {
+			return fmt.Errorf("Can't print report: %v", err)
+		}
+		return nil
+	}
+	fmt.Printf("Account: %s (%s)\n", result.Account.Username, result.Account.ID)
+	fmt.Printf("\nSubscriptions: %d\n", len(result.Subscriptions))
+	for _, sub := range result.Subscriptions {
+		fmt.Printf("  %s  cluster: %s  name: %s\n", sub.ID, valueOrNA(sub.ClusterID),
+			valueOrNA(sub.DisplayName))
+	}
+	fmt.Printf("\nClusters: %d\n", len(result.Clusters))
+	for _, cluster := range result.Clusters {
+		fmt.Printf("  %s  %s  %s\n", cluster.ID, cluster.Name, cluster.State)
+	}
+	fmt.Printf("\nRoles: %s\n", valueOrNA(strings.Join(result.Roles, ", ")))
+
+	return nil
+}
+
+// getClusters retrieves the clusters with the given quoted identifiers.
+func getClusters(connection *sdk.Connection, quotedIDs []string) ([]*cmv1.Cluster, error) {
+	var clusters []*cmv1.Cluster
+	search := fmt.Sprintf("id in (%s)", strings.Join(quotedIDs, ", "))
+	pageIndex := 1
+	for {
+		ctx, cancel := config.RequestContext(connection)
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search(search).
+			Size(100).
+			Page(pageIndex).
+			SendContext(ctx)
+		cancel()
+		if err != nil {
+			return clusters, fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < 100 {
+			break
+		}
+		pageIndex++
+	}
+	return clusters, nil
+}
+
+func valueOrNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	flags "github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	table "github.com/openshift-online/ocm-cli/pkg/table"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
@@ -65,30 +66,12 @@ func init() {
 
 func run(cmd *cobra.Command, argv []string) error {
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	// Indices
 	pageIndex := 1
@@ -120,7 +103,9 @@ func run(cmd *cobra.Command, argv []string) error {
 		flags.ApplyParameterFlag(request, args.parameter)
 
 		// Fetch next page
-		orgList, err := request.Send()
+		ctx, cancel := config.RequestContext(connection)
+		orgList, err := request.SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Failed to retrieve organization list: %v", err)
 		}
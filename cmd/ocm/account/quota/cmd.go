@@ -25,6 +25,7 @@ import (
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
@@ -59,38 +60,22 @@ func init() {
 
 func run(cmd *cobra.Command, argv []string) error {
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	orgID := args.org
 
 	// Organization to search in case one was not provided:
 	if args.org == "" {
 		// Get organization of current user:
+		ctx, cancel := config.RequestContext(connection)
 		userConn, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't retrieve current user information: %v", err)
 		}
@@ -100,7 +85,9 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Get connection
 	orgCollection := connection.AccountsMgmt().V1().Organizations().Organization(orgID)
-	orgResponse, err := orgCollection.Get().Send()
+	ctx, cancel := config.RequestContext(connection)
+	orgResponse, err := orgCollection.Get().SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't retrieve organization information: %v", err)
 	}
@@ -110,8 +97,10 @@ func run(cmd *cobra.Command, argv []string) error {
 	if !args.json {
 
 		// Request
+		ctx, cancel := config.RequestContext(connection)
 		quotasListResponse, err := quotaClient.List().
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Failed to retrieve quota: %v", err)
 		}
@@ -134,9 +123,11 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// TODO: Do this without hard-code; could not find any marshall method
+	ctx, cancel = config.RequestContext(connection)
 	jsonDisplay, err := connection.Get().Path(
 		fmt.Sprintf("/api/accounts_mgmt/v1/organizations/%s/resource_quota", orgID)).
-		Send()
+		SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Failed to get resource quota: %v", err)
 	}
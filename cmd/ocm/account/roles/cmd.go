@@ -17,25 +17,42 @@ limitations under the License.
 package roles
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	table "github.com/openshift-online/ocm-cli/pkg/table"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
 var args struct {
 	debug bool
+	mine  bool
 }
 
 var Cmd = &cobra.Command{
 	Use:   "roles [role-name]",
 	Short: "Retrieve information of the different roles",
-	Long:  "Get description of a role or list of all roles ",
-	RunE:  run,
+	Long: "Get description of a role or list of all roles. With the '--mine' option list " +
+		"the roles of the current user instead.",
+	RunE: run,
+}
+
+// roleBinding contains the details of one of the roles of the current user that are printed
+// when the '--mine' option is used.
+type roleBinding struct {
+	Role         string `json:"role"`
+	Type         string `json:"type"`
+	Organization string `json:"organization_id,omitempty"`
+	Subscription string `json:"subscription_id,omitempty"`
 }
 
 func init() {
@@ -47,34 +64,33 @@ func init() {
 		false,
 		"Enable debug mode.",
 	)
+	flags.BoolVar(
+		&args.mine,
+		"mine",
+		false,
+		"List the roles of the current user, with the type of each role and the "+
+			"organization or subscription that it applies to. Use the global "+
+			"'--output json' option to get them in JSON format.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
+	// Check the options:
+	if args.mine && len(argv) > 0 {
+		return fmt.Errorf("Option '--mine' can't be used with a role name")
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
+	// Create the connection, and remember to close it:
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+		return err
 	}
+	defer config.Close(connection)
 
-	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
-	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+	// Print the roles of the current user if requested:
+	if args.mine {
+		return printMine(cmd, connection)
 	}
-	defer connection.Close()
 
 	// No role name was provided; Print all roles.
 	var rolesList []string
@@ -82,7 +98,9 @@ func run(cmd *cobra.Command, argv []string) error {
 		pageIndex := 1
 		for {
 			rolesListRequest := connection.AccountsMgmt().V1().Roles().List().Page(pageIndex)
-			response, err := rolesListRequest.Send()
+			ctx, cancel := config.RequestContext(connection)
+			response, err := rolesListRequest.SendContext(ctx)
+			cancel()
 			if err != nil {
 				return fmt.Errorf("Can't send request: %v", err)
 			}
@@ -107,16 +125,20 @@ func run(cmd *cobra.Command, argv []string) error {
 	} else {
 
 		// Get role with provided id response:
+		ctx, cancel := config.RequestContext(connection)
 		roleResponse, err := connection.AccountsMgmt().V1().Roles().Role(argv[0]).Get().
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't send request: %v", err)
 		}
 		role := roleResponse.Body()
 
 		// Use role in new get request
+		ctx, cancel = config.RequestContext(connection)
 		byteRole, err := connection.Get().Path(role.HREF()).
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't send request: %v", err)
 		}
@@ -131,3 +153,54 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// printMine prints the roles of the current user, as a table or in JSON format if the global
+// '--output json' option has been used.
+func printMine(cmd *cobra.Command, connection *sdk.Connection) error {
+	// Get the current account:
+	ctx, cancel := config.RequestContext(connection)
+	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
+		SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't get current account: %v", err)
+	}
+
+	// Get the role bindings of the account:
+	bindings, err := acc_util.GetRoleBindingsFromUser(response.Body(), connection)
+	if err != nil {
+		return err
+	}
+	roles := make([]*roleBinding, len(bindings))
+	for i, binding := range bindings {
+		roles[i] = &roleBinding{
+			Role:         binding.Role().ID(),
+			Type:         binding.Type(),
+			Organization: binding.Organization().ID(),
+			Subscription: binding.Subscription().ID(),
+		}
+	}
+
+	// Print the roles:
+	if output.JSON() {
+		data, err := json.MarshalIndent(roles, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Can't marshal roles: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	padding := []int{30, 15, 35}
+	table.PrintPadded(os.Stdout, []string{"ROLE", "TYPE", "RESOURCE"}, padding)
+	for _, role := range roles {
+		resource := role.Subscription
+		if resource == "" {
+			resource = role.Organization
+		}
+		if resource == "" {
+			resource = "-"
+		}
+		table.PrintPadded(os.Stdout, []string{role.Role, role.Type, resource}, padding)
+	}
+	return nil
+}
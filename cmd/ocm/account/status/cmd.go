@@ -23,6 +23,7 @@ import (
 
 	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 )
 
 var args struct {
@@ -50,33 +51,23 @@ func init() {
 func run(cmd *cobra.Command, argv []string) error {
 
 	// Load the configuration file:
-	cfg, err := config.Load()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+		return err
 	}
 
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.ConnectionFor(cfg)
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	// Send the request:
+	ctx, cancel := config.RequestContext(connection)
 	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
-		Send()
+		SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't get current account: %v", err)
 	}
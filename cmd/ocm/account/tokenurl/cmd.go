@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenurl
+
+import (
+	"fmt"
+	"os"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/pkg/browser"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var args struct {
+	url  string
+	open bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "generate-token-url",
+	Short: "Print the URL of the offline access token page",
+	Long: "Print the URL of the page where the offline access token for the API gateway " +
+		"can be obtained. The gateway is the one of the configuration file, or the " +
+		"production gateway if not logged in.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.url,
+		"url",
+		"",
+		"URL of the API gateway. The default is the one from the configuration file.",
+	)
+	flags.BoolVar(
+		&args.open,
+		"open",
+		false,
+		"Open the page in the default browser.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Find the URL of the gateway, from the command line or from the configuration file:
+	gateway := args.url
+	if gateway == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("Can't load config file: %v", err)
+		}
+		if cfg != nil {
+			gateway = cfg.URL
+		}
+	}
+	if gateway == "" {
+		gateway = sdk.DefaultURL
+	}
+
+	// Print or open the page:
+	page := urls.TokenPage(gateway)
+	if args.open {
+		err := browser.OpenURL(page)
+		if err == nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Can't open the page in the browser: %v\n", err)
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", page)
+
+	return nil
+}
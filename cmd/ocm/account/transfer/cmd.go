@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	to      string
+	cluster bool
+	yes     bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "transfer-ownership SUBSCRIPTION_ID --to ACCOUNT_ID",
+	Short: "Transfer the ownership of a subscription",
+	Long: "Transfer the ownership of a subscription, and therefore of the cluster that it " +
+		"corresponds to, to another account. This requires privileges to update the " +
+		"subscription.",
+	Example: " ocm account transfer-ownership <subscription id> --to <account id>\n" +
+		" ocm account transfer-ownership --cluster <cluster id> --to <account id>",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Identifier of the account that will be the new owner.",
+	)
+	flags.BoolVar(
+		&args.cluster,
+		"cluster",
+		false,
+		"Interpret the argument as a cluster identifier instead of a subscription identifier.",
+	)
+	flags.BoolVar(
+		&args.yes,
+		"yes",
+		false,
+		"Don't ask for confirmation.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check mandatory options:
+	if args.to == "" {
+		return fmt.Errorf("Option '--to' is mandatory")
+	}
+
+	// Create the connection, and remember to close it:
+	connection, err := ocm.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer config.Close(connection)
+
+	// Find the identifier of the subscription:
+	subID := argv[0]
+	if args.cluster {
+		ctx, cancel := config.RequestContext(connection)
+		clusterResponse, err := connection.ClustersMgmt().V1().
+			Clusters().
+			Cluster(argv[0]).
+			Get().
+			SendContext(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("Can't get cluster '%s': %v", argv[0], err)
+		}
+		subID = clusterResponse.Body().Subscription().ID()
+		if subID == "" {
+			return fmt.Errorf("Cluster '%s' doesn't have a subscription", argv[0])
+		}
+	}
+
+	// Retrieve the subscription:
+	subsResource := connection.AccountsMgmt().V1().Subscriptions()
+	ctx, cancel := config.RequestContext(connection)
+	subResponse, err := subsResource.Subscription(subID).Get().SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't get subscription '%s': %v", subID, err)
+	}
+	sub := subResponse.Body()
+
+	// Retrieve the current owner and check that the target account exists:
+	accountsResource := connection.AccountsMgmt().V1().Accounts()
+	var from *amv1.Account
+	fromID := sub.Creator().ID()
+	if fromID != "" {
+		ctx, cancel := config.RequestContext(connection)
+		fromResponse, err := accountsResource.Account(fromID).Get().SendContext(ctx)
+		cancel()
+		if err != nil {
+			if fromResponse == nil || fromResponse.Status() != 404 {
+				return fmt.Errorf("Can't get account '%s': %v", fromID, err)
+			}
+		}
+		from = fromResponse.Body()
+	}
+	ctx, cancel = config.RequestContext(connection)
+	toResponse, err := accountsResource.Account(args.to).Get().SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't get target account '%s': %v", args.to, err)
+	}
+	to := toResponse.Body()
+	if to.ID() == fromID {
+		return fmt.Errorf(
+			"Account '%s' is already the owner of subscription '%s'",
+			describeAccount(to), subID,
+		)
+	}
+
+	// Ask for confirmation:
+	fmt.Printf(
+		"Subscription: %s\n"+
+			"Cluster:      %s\n"+
+			"Owner:        %s\n"+
+			"New owner:    %s\n",
+		subID,
+		sub.ClusterID(),
+		describeAccount(from),
+		describeAccount(to),
+	)
+	if !args.yes {
+		confirmed := false
+		prompt := &survey.Confirm{
+			Message: "Transfer the ownership?",
+		}
+		err = survey.AskOne(prompt, &confirmed, nil)
+		if err != nil {
+			return fmt.Errorf("Can't ask for confirmation: %v", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	// Update the creator of the subscription:
+	body, err := json.Marshal(map[string]interface{}{
+		"creator": map[string]interface{}{
+			"id": to.ID(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Can't create request body: %v", err)
+	}
+	ctx, cancel = config.RequestContext(connection)
+	response, err := connection.Patch().
+		Path(sub.HREF()).
+		Bytes(body).
+		SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't send request: %v", err)
+	}
+	if response.Status() >= 400 {
+		err = dump.Pretty(os.Stderr, response.Bytes())
+		if err != nil {
+			return fmt.Errorf("Can't print body: %v", err)
+		}
+		return fmt.Errorf("Can't update subscription '%s'", subID)
+	}
+
+	// Retrieve the subscription again to show the result:
+	ctx, cancel = config.RequestContext(connection)
+	subResponse, err = subsResource.Subscription(subID).Get().SendContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't get subscription '%s': %v", subID, err)
+	}
+	owner := subResponse.Body().Creator().ID()
+	if owner != to.ID() {
+		return fmt.Errorf(
+			"Owner of subscription '%s' is still '%s' instead of '%s', the transfer "+
+				"didn't take effect",
+			subID, owner, describeAccount(to),
+		)
+	}
+	fmt.Printf(
+		"Owner of subscription '%s' changed from '%s' to '%s'\n",
+		subID, describeAccount(from), describeAccount(to),
+	)
+
+	return nil
+}
+
+// describeAccount returns a short description of the account containing the user name and the
+// identifier.
+func describeAccount(account *amv1.Account) string {
+	if account == nil || account.ID() == "" {
+		return "N/A"
+	}
+	if account.Username() == "" {
+		return account.ID()
+	}
+	return fmt.Sprintf("%s (%s)", account.Username(), account.ID())
+}
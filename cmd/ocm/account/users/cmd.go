@@ -25,6 +25,7 @@ import (
 
 	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
@@ -59,30 +60,12 @@ func init() {
 
 func run(cmd *cobra.Command, argv []string) error {
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	// needed variables:
 	pageSize := 100
@@ -92,8 +75,10 @@ func run(cmd *cobra.Command, argv []string) error {
 	// Organization to search in case one was not provided:
 	if args.org == "" {
 		// Get organization of current user:
+		ctx, cancel := config.RequestContext(connection)
 		userConn, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't retrieve current user information: %v", err)
 		}
@@ -115,11 +100,13 @@ func run(cmd *cobra.Command, argv []string) error {
 		searchQuery := fmt.Sprintf("organization_id='%s'", args.org)
 
 		// Get all users within organization
+		ctx, cancel := config.RequestContext(connection)
 		usersResponse, err := connection.AccountsMgmt().V1().Accounts().List().
 			Size(pageSize).
 			Page(pageIndex).
 			Parameter("search", searchQuery).
-			Send()
+			SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't retrieve accounts: %v", err)
 		}
@@ -18,27 +18,45 @@ package describe
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 
+	clusterpkg "github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/table"
+	"github.com/openshift-online/ocm-cli/pkg/times"
 )
 
 var args struct {
-	json   bool
-	output bool
+	json         bool
+	jsonFile     bool
+	openConsole  bool
+	openAPI      bool
+	fetchTimeout time.Duration
+	relative     bool
+	checks       []string
+	noCache      bool
+	includeHREFs bool
+	summaryOnly  bool
+	exportTF     bool
 }
 
 var Cmd = &cobra.Command{
-	Use:   "describe CLUSTERID [--output] [--short]",
+	Use:   "describe [CLUSTERID|CLUSTER_NAME] [--json-file] [--short]",
 	Short: "Describe a cluster",
-	Long:  "Get info about a cluster identified by its cluster ID",
+	Long:  "Get info about a cluster identified by its cluster ID or name",
 	RunE:  run,
 }
 
@@ -46,8 +64,8 @@ func init() {
 	// Add flags to rootCmd:
 	flags := Cmd.Flags()
 	flags.BoolVar(
-		&args.output,
-		"output",
+		&args.jsonFile,
+		"json-file",
 		false,
 		"Output result into JSON file.",
 	)
@@ -57,6 +75,74 @@ func init() {
 		false,
 		"Output the entire JSON structure",
 	)
+	flags.BoolVar(
+		&args.openConsole,
+		"open-console",
+		false,
+		"Open the console of the cluster in the default browser. If the browser can't be "+
+			"opened the URL will be printed instead.",
+	)
+	flags.BoolVar(
+		&args.openAPI,
+		"open-api",
+		false,
+		"Open the API URL of the cluster in the default browser. If the browser can't be "+
+			"opened the URL will be printed instead.",
+	)
+	flags.DurationVar(
+		&args.fetchTimeout,
+		"fetch-timeout",
+		0,
+		"Maximum time to wait for each of the requests sent to retrieve the cluster, its "+
+			"subscription and its creator. The limit applies to each request separately, "+
+			"not to the complete command. The default is to use the general '--timeout' "+
+			"option.",
+	)
+	flags.BoolVar(
+		&args.relative,
+		"relative-times",
+		false,
+		"Display times relative to the current time, for example '3 days ago'. The JSON "+
+			"output always contains the absolute times.",
+	)
+	flags.StringArrayVar(
+		&args.checks,
+		"check",
+		nil,
+		"Check that a field of the cluster has the given value, for example "+
+			"'.state=ready' or '.nodes.compute=6', instead of describing the cluster. "+
+			"The command fails listing the checks that don't match. Can be repeated "+
+			"multiple times, and all the checks need to match.",
+	)
+	flags.BoolVar(
+		&args.noCache,
+		"no-cache",
+		false,
+		"Don't use the cache of cluster identifiers when the cluster is given by name.",
+	)
+	flags.BoolVar(
+		&args.includeHREFs,
+		"include-hrefs",
+		true,
+		"Include the 'kind' and 'href' fields in the JSON output. Use "+
+			"'--include-hrefs=false' to remove them from all the objects, so that links "+
+			"are reduced to their identifiers.",
+	)
+	flags.BoolVar(
+		&args.summaryOnly,
+		"summary-only",
+		false,
+		"Print only one line containing the name, state, version, region and number of "+
+			"compute nodes of the cluster.",
+	)
+	flags.BoolVar(
+		&args.exportTF,
+		"export-tf",
+		false,
+		"Print a Terraform resource block for the OCM provider that reflects the "+
+			"settings of the cluster. This is a best effort starting point, settings "+
+			"that can't be mapped are included as comments.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -64,49 +150,67 @@ func run(cmd *cobra.Command, argv []string) error {
 	if len(argv) != 1 {
 		return fmt.Errorf("Expected exactly one cluster")
 	}
-
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+	if args.openConsole && args.openAPI {
+		return fmt.Errorf("Options '--open-console' and '--open-api' are mutually exclusive")
 	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
+	if args.summaryOnly && args.json {
+		return fmt.Errorf("Options '--summary-only' and '--json' are mutually exclusive")
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
+	// Create the connection, and remember to close it:
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
+		return err
 	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+	defer config.Close(connection)
+
+	// Retrieve the cluster:
+	var cluster *cmv1.Cluster
+	ctx, cancel := fetchContext(connection)
+	if args.noCache {
+		resource := connection.ClustersMgmt().V1().Clusters()
+		cluster, err = clusterpkg.GetCluster(ctx, resource, argv[0])
+	} else {
+		cluster, err = clusterpkg.GetClusterCached(ctx, connection, argv[0])
 	}
-
-	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	cancel()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return fmt.Errorf("Can't retrieve cluster: %v", err)
 	}
-	defer connection.Close()
-
-	// Get the client for the resource that manages the collection of clusters:
-	resource := connection.ClustersMgmt().V1().Clusters()
 
-	// Get the resource that manages the cluster that we want to display:
-	clusterResource := resource.Cluster(argv[0])
+	// Check the values of the fields if requested:
+	if len(args.checks) > 0 {
+		return checkCluster(cluster)
+	}
 
-	// Retrieve the collection of clusters:
-	response, err := clusterResource.Get().Send()
+	// Print only the summary line if requested:
+	if args.summaryOnly {
+		fmt.Printf(
+			"%s %s %s %s %d\n",
+			valueOrNA(cluster.Name()),
+			valueOrNA(string(cluster.State())),
+			valueOrNA(cluster.OpenshiftVersion()),
+			valueOrNA(cluster.Region().ID()),
+			cluster.Nodes().Compute(),
+		)
+		return nil
+	}
 
-	// Get cluster body:
-	cluster := response.Body()
+	// Print the Terraform resource if requested:
+	if args.exportTF {
+		exportTerraform(os.Stdout, cluster)
+		return nil
+	}
 
-	if err != nil {
-		return fmt.Errorf("Can't retrieve clusters: %s", err)
+	// Open the console or API URL if requested:
+	if args.openConsole {
+		return openURL(cluster.Console().URL(), "console", cluster)
+	}
+	if args.openAPI {
+		return openURL(cluster.API().URL(), "API", cluster)
 	}
 
-	if args.output {
+	if args.jsonFile {
 		// Create a filename based on cluster name:
 		filename := fmt.Sprintf("cluster-%s.json", cluster.ID())
 
@@ -116,34 +220,38 @@ func run(cmd *cobra.Command, argv []string) error {
 			return fmt.Errorf("Failed to create file: %v", err)
 		}
 
-		// Reasign encoder io.Writer to file writer:
-		encoder := json.NewEncoder(myFile)
-		encoder.SetIndent("", " ")
+		defer myFile.Close()
 
-		// Dump encoder content into file:
-		err = cmv1.MarshalCluster(cluster, encoder)
+		// Convert cluster to JSON, removing the links if requested:
+		body, err := marshalCluster(cluster)
 		if err != nil {
 			return fmt.Errorf("Failed to Marshal cluster into file: %v", err)
 		}
+
+		// Dump indented content into file:
+		buf := new(bytes.Buffer)
+		err = json.Indent(buf, body, "", " ")
+		if err != nil {
+			return fmt.Errorf("Failed to indent cluster JSON: %v", err)
+		}
+		buf.WriteString("\n")
+		_, err = buf.WriteTo(myFile)
+		if err != nil {
+			return fmt.Errorf("Failed to write file: %v", err)
+		}
 	}
 
 	// Get full API response (JSON):
 	if args.json {
-		// Buffer for pretty output:
-		buf := new(bytes.Buffer)
 		fmt.Println()
 
-		// Convert cluster to JSON and dump to encoder:
-		err = cmv1.MarshalCluster(cluster, buf)
+		// Convert cluster to JSON, removing the links if requested:
+		body, err := marshalCluster(cluster)
 		if err != nil {
 			return fmt.Errorf("Failed to Marshal cluster into JSON encoder: %v", err)
 		}
 
-		if response.Status() < 400 {
-			err = dump.Pretty(os.Stdout, buf.Bytes())
-		} else {
-			err = dump.Pretty(os.Stderr, buf.Bytes())
-		}
+		err = dump.Pretty(os.Stdout, body)
 		if err != nil {
 			return fmt.Errorf("Can't print body: %v", err)
 		}
@@ -152,55 +260,19 @@ func run(cmd *cobra.Command, argv []string) error {
 		// Get creation date info:
 		clusterTimetamp := cluster.CreationTimestamp()
 		year, month, day := clusterTimetamp.Date()
+		created := fmt.Sprintf("%s %d %d", month.String(), day, year)
+		if args.relative {
+			created = times.Relative(clusterTimetamp, time.Now())
+		}
 
 		// Get API URL:
 		api := cluster.API()
 		apiURL, _ := api.GetURL()
 
-		// Retrieve the details of the subscription:
-		var sub *amv1.Subscription
-		subID := cluster.Subscription().ID()
-		if subID != "" {
-			subResponse, err := connection.AccountsMgmt().V1().
-				Subscriptions().
-				Subscription(subID).
-				Get().
-				Send()
-			if err != nil {
-				if subResponse == nil || subResponse.Status() != 404 {
-					return fmt.Errorf(
-						"can't get subscription '%s': %v",
-						subID, err,
-					)
-				}
-			}
-			sub = subResponse.Body()
-		}
-
-		// Retrieve the details of the account:
-		var account *amv1.Account
-		accountID := sub.Creator().ID()
-		if accountID != "" {
-			accountResponse, err := connection.AccountsMgmt().V1().
-				Accounts().
-				Account(accountID).
-				Get().
-				Send()
-			if err != nil {
-				if accountResponse == nil || accountResponse.Status() != 404 {
-					return fmt.Errorf(
-						"can't get account '%s': %v",
-						accountID, err,
-					)
-				}
-			}
-			account = accountResponse.Body()
-		}
-
 		// Find the details of the creator:
-		creator := account.Username()
-		if creator == "" {
-			creator = "N/A"
+		creator, err := fetchCreator(connection, cluster)
+		if err != nil {
+			return err
 		}
 
 		// Print short cluster description:
@@ -212,7 +284,7 @@ func run(cmd *cobra.Command, argv []string) error {
 			"Region:   %s\n"+
 			"Multi-az: %t\n"+
 			"Creator:  %s\n"+
-			"Created:  %s %d %d\n",
+			"Created:  %s\n",
 			cluster.ID(),
 			cluster.Name(),
 			cluster.DNS().BaseDomain(),
@@ -222,10 +294,146 @@ func run(cmd *cobra.Command, argv []string) error {
 			cluster.Region().ID(),
 			cluster.MultiAZ(),
 			creator,
-			month.String(), day, year,
+			created,
 		)
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// fetchCreator retrieves the subscription of the cluster and the account that created it, and
+// returns the user name of that account, or 'N/A' if it isn't available.
+func fetchCreator(connection *sdk.Connection, cluster *cmv1.Cluster) (creator string, err error) {
+	// Retrieve the details of the subscription:
+	var sub *amv1.Subscription
+	subID := cluster.Subscription().ID()
+	if subID != "" {
+		ctx, cancel := fetchContext(connection)
+		subResponse, err := connection.AccountsMgmt().V1().
+			Subscriptions().
+			Subscription(subID).
+			Get().
+			SendContext(ctx)
+		cancel()
+		if err != nil {
+			if subResponse == nil || subResponse.Status() != 404 {
+				return "", fmt.Errorf(
+					"can't get subscription '%s': %v",
+					subID, err,
+				)
+			}
+		}
+		sub = subResponse.Body()
+	}
+
+	// Retrieve the details of the account:
+	var account *amv1.Account
+	accountID := sub.Creator().ID()
+	if accountID != "" {
+		ctx, cancel := fetchContext(connection)
+		accountResponse, err := connection.AccountsMgmt().V1().
+			Accounts().
+			Account(accountID).
+			Get().
+			SendContext(ctx)
+		cancel()
+		if err != nil {
+			if accountResponse == nil || accountResponse.Status() != 404 {
+				return "", fmt.Errorf(
+					"can't get account '%s': %v",
+					accountID, err,
+				)
+			}
+		}
+		account = accountResponse.Body()
+	}
+
+	creator = valueOrNA(account.Username())
+	return
+}
+
+func valueOrNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
+
+// fetchContext returns the context for one of the requests sent with the given connection to
+// retrieve the cluster and the related objects, with the timeout given in the '--fetch-timeout'
+// option, or with the general request timeout if that option isn't used.
+func fetchContext(connection *sdk.Connection) (ctx context.Context, cancel context.CancelFunc) {
+	if args.fetchTimeout > 0 {
+		return context.WithTimeout(context.Background(), args.fetchTimeout)
+	}
+	return config.RequestContext(connection)
+}
+
+// marshalCluster converts the cluster to JSON, removing the 'kind' and 'href' fields unless the
+// '--include-hrefs' option is true.
+func marshalCluster(cluster *cmv1.Cluster) (body []byte, err error) {
+	buf := new(bytes.Buffer)
+	err = cmv1.MarshalCluster(cluster, buf)
+	if err != nil {
+		return
+	}
+	body = buf.Bytes()
+	if !args.includeHREFs {
+		body, err = dump.StripLinks(body)
+	}
+	return
+}
+
+// checkCluster checks that the fields of the cluster have the values given in the '--check'
+// options. It returns an error listing the checks that don't match.
+func checkCluster(cluster *cmv1.Cluster) error {
+	// Convert the cluster to a map, so that the fields can be located by path:
+	buffer := new(bytes.Buffer)
+	err := cmv1.MarshalCluster(cluster, buffer)
+	if err != nil {
+		return fmt.Errorf("Failed to Marshal cluster into JSON encoder: %v", err)
+	}
+	var data map[string]interface{}
+	err = json.Unmarshal(buffer.Bytes(), &data)
+	if err != nil {
+		return fmt.Errorf("Failed to turn cluster bytes into JSON map: %v", err)
+	}
+
+	// Verify each of the checks:
+	failed := 0
+	for _, check := range args.checks {
+		position := strings.Index(check, "=")
+		if position <= 0 {
+			return fmt.Errorf("Check '%s' should be in the 'path=value' format", check)
+		}
+		path := strings.TrimPrefix(check[:position], ".")
+		expected := check[position+1:]
+		actual, ok := table.FindMapValue(data, path)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Check '%s' failed: field doesn't exist\n", check)
+			failed++
+		} else if actual != expected {
+			fmt.Fprintf(os.Stderr, "Check '%s' failed: value is '%s'\n", check, actual)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(args.checks))
+	}
+	return nil
+}
+
+// openURL opens the given URL of the cluster in the default browser. If that isn't possible, for
+// example because there is no graphical environment, it prints the URL instead.
+func openURL(url string, what string, cluster *cmv1.Cluster) error {
+	if url == "" {
+		return fmt.Errorf("Cannot find the %s URL for cluster '%s'", what, cluster.Name())
+	}
+	err := browser.OpenURL(url)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't open the %s URL in the browser: %v\n", what, err)
+		fmt.Fprintf(os.Stdout, "%s\n", url)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the '--export-tf' option of the 'describe' command.
+
+package describe
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// terraformInvalidChars matches the characters that can't be used in Terraform resource names.
+var terraformInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// exportTerraform writes to the given stream a Terraform resource block for the 'ocm_cluster'
+// resource of the OCM Terraform provider that describes the given cluster. This is only a best
+// effort starting point: settings of the cluster that don't have a corresponding attribute in
+// the resource are written as comments.
+func exportTerraform(stream io.Writer, cluster *cmv1.Cluster) {
+	var attributes [][2]string
+	var unmapped [][2]string
+	addString := func(list *[][2]string, name string, value string, ok bool) {
+		if ok && value != "" {
+			*list = append(*list, [2]string{name, strconv.Quote(value)})
+		}
+	}
+	addNumber := func(list *[][2]string, name string, value int, ok bool) {
+		if ok {
+			*list = append(*list, [2]string{name, strconv.Itoa(value)})
+		}
+	}
+	addBool := func(list *[][2]string, name string, value bool, ok bool) {
+		if ok {
+			*list = append(*list, [2]string{name, strconv.FormatBool(value)})
+		}
+	}
+
+	// Settings that have a corresponding attribute:
+	value, ok := cluster.GetName()
+	addString(&attributes, "name", value, ok)
+	value, ok = cluster.CloudProvider().GetID()
+	addString(&attributes, "cloud_provider", value, ok)
+	value, ok = cluster.Region().GetID()
+	addString(&attributes, "cloud_region", value, ok)
+	multiAZ, ok := cluster.GetMultiAZ()
+	addBool(&attributes, "multi_az", multiAZ, ok)
+	compute, ok := cluster.Nodes().GetCompute()
+	addNumber(&attributes, "compute_nodes", compute, ok)
+	value, ok = cluster.Version().GetID()
+	addString(&attributes, "version", value, ok)
+	value, ok = cluster.Network().GetMachineCIDR()
+	addString(&attributes, "machine_cidr", value, ok)
+	value, ok = cluster.Network().GetServiceCIDR()
+	addString(&attributes, "service_cidr", value, ok)
+	value, ok = cluster.Network().GetPodCIDR()
+	addString(&attributes, "pod_cidr", value, ok)
+
+	// Settings that don't have a corresponding attribute:
+	value, ok = cluster.GetDisplayName()
+	addString(&unmapped, "display_name", value, ok)
+	value, ok = cluster.DNS().GetBaseDomain()
+	addString(&unmapped, "dns.base_domain", value, ok)
+	value, ok = cluster.Flavour().GetID()
+	addString(&unmapped, "flavour.id", value, ok)
+	master, ok := cluster.Nodes().GetMaster()
+	addNumber(&unmapped, "nodes.master", master, ok)
+	infra, ok := cluster.Nodes().GetInfra()
+	addNumber(&unmapped, "nodes.infra", infra, ok)
+	managed, ok := cluster.GetManaged()
+	addBool(&unmapped, "managed", managed, ok)
+	expiration, ok := cluster.GetExpirationTimestamp()
+	if ok {
+		unmapped = append(unmapped, [2]string{
+			"expiration_timestamp",
+			strconv.Quote(expiration.Format(time.RFC3339)),
+		})
+	}
+
+	// Calculate the column where values start, so that the block is aligned like the output of
+	// 'terraform fmt':
+	width := 0
+	for _, attribute := range attributes {
+		if len(attribute[0]) > width {
+			width = len(attribute[0])
+		}
+	}
+	properties := cluster.Properties()
+	if len(properties) > 0 && width < len("properties") {
+		width = len("properties")
+	}
+
+	// Write the block:
+	name := terraformInvalidChars.ReplaceAllString(cluster.Name(), "_")
+	if name == "" {
+		name = "cluster"
+	}
+	fmt.Fprintf(stream, "resource \"ocm_cluster\" %s {\n", strconv.Quote(name))
+	for _, attribute := range attributes {
+		fmt.Fprintf(stream, "  %-*s = %s\n", width, attribute[0], attribute[1])
+	}
+	if len(properties) > 0 {
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(stream, "  %-*s = {\n", width, "properties")
+		for _, key := range keys {
+			fmt.Fprintf(stream, "    %s = %s\n", strconv.Quote(key), strconv.Quote(properties[key]))
+		}
+		fmt.Fprintf(stream, "  }\n")
+	}
+	if len(unmapped) > 0 {
+		fmt.Fprintf(stream, "\n")
+		fmt.Fprintf(stream, "  # The following settings of the cluster couldn't be mapped to\n")
+		fmt.Fprintf(stream, "  # attributes of the resource:\n")
+		for _, setting := range unmapped {
+			fmt.Fprintf(stream, "  # %s = %s\n", setting[0], setting[1])
+		}
+	}
+	fmt.Fprintf(stream, "}\n")
+}
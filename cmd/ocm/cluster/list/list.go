@@ -24,13 +24,19 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/search"
 	table "github.com/openshift-online/ocm-cli/pkg/table"
+	"github.com/openshift-online/ocm-cli/pkg/times"
 )
 
 var args struct {
@@ -40,10 +46,19 @@ var args struct {
 	step      bool
 	columns   string
 	padding   int
+	owner     string
+	relative  bool
 }
 
 var managed bool
 
+// timeColumns are the columns that contain times, and that will be displayed relative to the
+// current time when the '--relative-times' option is used.
+var timeColumns = map[string]bool{
+	"creation_timestamp":   true,
+	"expiration_timestamp": true,
+}
+
 // Cmd Constant:
 var Cmd = &cobra.Command{
 	Use:   "list [flags] [partial cluster ID or name]",
@@ -83,36 +98,28 @@ func init() {
 		-1,
 		"Change all column sizes.",
 	)
+	fs.StringVar(
+		&args.owner,
+		"owner",
+		"",
+		"Only list the clusters owned by the given user name. Use 'me' for the clusters "+
+			"owned by the current user.",
+	)
+	fs.BoolVar(
+		&args.relative,
+		"relative-times",
+		false,
+		"Display times relative to the current time, for example '3 days ago'.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
-
-	// Get the client for the resource that manages the collection of clusters:
-	collection := connection.ClustersMgmt().V1().Clusters()
+	defer config.Close(connection)
 
 	if cmd.Flags().Changed("managed") {
 		managed = args.managed
@@ -126,6 +133,30 @@ func run(cmd *cobra.Command, argv []string) error {
 		argFilter = fmt.Sprintf("(name like '%%%s%%' or id like '%%%s%%')", argv[0], argv[0])
 	}
 
+	// If an owner has been specified find the clusters of the subscriptions that it created:
+	var ownerFilters []string
+	if args.owner != "" {
+		ownerFilters, err = makeOwnerFilters(connection)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Combine the filters. When there is an owner there is one filter for each of the groups of
+	// clusters that it owns, and none if it doesn't own any cluster:
+	var managedFilter string
+	if managed {
+		managedFilter = "managed = 't'"
+	}
+	var filters []string
+	if args.owner != "" {
+		for _, ownerFilter := range ownerFilters {
+			filters = append(filters, joinFilters(managedFilter, argFilter, ownerFilter))
+		}
+	} else {
+		filters = append(filters, joinFilters(managedFilter, argFilter))
+	}
+
 	// Update our column name and padding variables:
 	args.columns = strings.Replace(args.columns, " ", "", -1)
 	colUpper := strings.ToUpper(args.columns)
@@ -143,6 +174,25 @@ func run(cmd *cobra.Command, argv []string) error {
 	table.PrintPadded(os.Stdout, columnNames, paddingByColumn)
 	fmt.Println()
 
+	now := time.Now()
+	for _, filter := range filters {
+		err = listPages(connection, filter, columnNames, paddingByColumn, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+
+	return nil
+}
+
+// listPages retrieves and prints all the pages of clusters that match the given search filter.
+func listPages(connection *sdk.Connection, filter string, columnNames []string,
+	paddingByColumn []int, now time.Time) error {
+	// Get the client for the resource that manages the collection of clusters:
+	collection := connection.ClustersMgmt().V1().Clusters()
+
 	size := 100
 	index := 1
 	for {
@@ -150,33 +200,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		request := collection.List().Size(size).Page(index)
 		flags.ApplyParameterFlag(request, args.parameter)
 		flags.ApplyHeaderFlag(request, args.header)
-		var search strings.Builder
-		if managed {
-			if search.Len() > 0 {
-				_, err = search.WriteString(" and ")
-				if err != nil {
-					return fmt.Errorf("Can't write to string: %v", err)
-				}
-			}
-			_, err = search.WriteString("managed = 't'")
-			if err != nil {
-				return fmt.Errorf("Can't write to string: %v", err)
-			}
-		}
-		if argFilter != "" {
-			if search.Len() > 0 {
-				_, err = search.WriteString(" and ")
-				if err != nil {
-					return fmt.Errorf("Can't write to string: %v", err)
-				}
-			}
-			_, err = search.WriteString(argFilter)
-			if err != nil {
-				return fmt.Errorf("Can't write to string: %v", err)
-			}
-		}
-		request.Search(strings.TrimSpace(search.String()))
-		response, err := request.Send()
+		request.Search(filter)
+		ctx, cancel := config.RequestContext(connection)
+		response, err := request.SendContext(ctx)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("Can't retrieve clusters: %v", err)
 		}
@@ -212,6 +239,8 @@ func run(cmd *cobra.Command, argv []string) error {
 				value, status := table.FindMapValue(jsonBody, element)
 				if !status {
 					value = "NONE"
+				} else if args.relative && timeColumns[element] {
+					value = times.RelativeText(value, now)
 				}
 				thisCluster = append(thisCluster, value)
 			}
@@ -248,11 +277,61 @@ func run(cmd *cobra.Command, argv []string) error {
 		index++
 	}
 
-	fmt.Println()
-
 	return nil
 }
 
+// ownerChunkSize is the maximum number of cluster identifiers included in each of the search
+// filters used for the '--owner' option, so that the length of the query stays bounded no matter
+// how many clusters the owner has.
+const ownerChunkSize = 50
+
+// makeOwnerFilters creates the search filters that select the clusters of the subscriptions
+// created by the owner given in the '--owner' option. There is one filter for each chunk of
+// identifiers, and no filter at all if the owner doesn't have clusters.
+func makeOwnerFilters(connection *sdk.Connection) (filters []string, err error) {
+	accountID, err := account.GetAccountID(args.owner, connection)
+	if err != nil {
+		return
+	}
+	clusterIDs, err := account.GetClusterIDsFromCreator(accountID, connection)
+	if err != nil {
+		return
+	}
+	filters = chunkFilters(clusterIDs, ownerChunkSize)
+	return
+}
+
+// chunkFilters splits the given cluster identifiers in chunks of at most the given size, and
+// returns an 'id in (...)' search filter for each chunk.
+func chunkFilters(clusterIDs []string, size int) []string {
+	var filters []string
+	for len(clusterIDs) > 0 {
+		chunk := clusterIDs
+		if len(chunk) > size {
+			chunk = chunk[:size]
+		}
+		clusterIDs = clusterIDs[len(chunk):]
+		quoted := make([]string, len(chunk))
+		for i, clusterID := range chunk {
+			quoted[i] = search.Quote(clusterID)
+		}
+		filters = append(filters, fmt.Sprintf("id in (%s)", strings.Join(quoted, ", ")))
+	}
+	return filters
+}
+
+// joinFilters combines the given search filters with the 'and' operator, ignoring the ones that
+// are empty.
+func joinFilters(filters ...string) string {
+	var parts []string
+	for _, filter := range filters {
+		if filter != "" {
+			parts = append(parts, filter)
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
 // clearPage clears the page.
 func clearPage() error {
 	// #nosec 204
@@ -23,12 +23,15 @@ import (
 	"os/exec"
 	"strings"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/search"
 )
 
 var args struct {
@@ -75,34 +78,16 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("To run this, you need install the OpenShift CLI (oc) first")
 	}
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	// Get the client for the resource that manages the collection of clusters:
 	collection := connection.ClustersMgmt().V1().Clusters()
-	clusters, total, err := findClusters(collection, argv[0], ClustersPageSize)
+	clusters, total, err := findClusters(connection, collection, argv[0], ClustersPageSize)
 	if err != nil || len(clusters) == 0 {
 		return fmt.Errorf("Can't find clusters: %v", err)
 	}
@@ -191,11 +176,14 @@ func doSurvey(clusters []*v1.Cluster) (cluster *v1.Cluster, err error) {
 // identifier is that key, or if its name starts with that key. For example, the key `prd-2305`
 // doesn't match a cluster directly because it isn't a valid identifier, but it matches all clusters
 // whose names start with `prd-2305`.
-func findClusters(collection *v1.ClustersClient, key string, size int) (clusters []*v1.Cluster, total int, err error) {
+func findClusters(connection *sdk.Connection, collection *v1.ClustersClient, key string,
+	size int) (clusters []*v1.Cluster, total int, err error) {
 
 	// Get the resource that manages the cluster that we want to display:
 	clusterResource := collection.Cluster(key)
-	response, err := clusterResource.Get().Send()
+	ctx, cancel := config.RequestContext(connection)
+	response, err := clusterResource.Get().SendContext(ctx)
+	cancel()
 
 	if err == nil && response != nil {
 		cluster := response.Body()
@@ -212,8 +200,10 @@ func findClusters(collection *v1.ClustersClient, key string, size int) (clusters
 	listRequest := collection.List().
 		Size(size).
 		Page(pageIndex)
-	listRequest.Search("name like '" + key + "'")
-	listResponse, err := listRequest.Send()
+	listRequest.Search("name like " + search.Quote(key))
+	ctx, cancel = config.RequestContext(connection)
+	listResponse, err := listRequest.SendContext(ctx)
+	cancel()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Can't retrieve clusters: %s\n", err)
 		return
@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 )
 
 var Cmd = &cobra.Command{
@@ -37,30 +38,12 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Expected exactly one cluster")
 	}
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
-
 	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
-	defer connection.Close()
+	defer config.Close(connection)
 
 	// Get the client for the resource that manages the collection of clusters:
 	resource := connection.ClustersMgmt().V1().Clusters()
@@ -69,8 +52,10 @@ func run(cmd *cobra.Command, argv []string) error {
 	clusterResource := resource.Cluster(argv[0])
 
 	// Retrieve the collection of clusters:
+	ctx, cancel := config.RequestContext(connection)
 	response, err := clusterResource.Get().
-		Send()
+		SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't retrieve clusters: %s", err)
 	}
@@ -19,10 +19,24 @@ package completion
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// wordsFunction is the name of the bash function that completes the values of flags that have a
+// fixed list of words.
+const wordsFunction = "__ocm_complete_words"
+
+// bashFunctions contains the bash functions used by the custom completions of the flags.
+const bashFunctions = `
+` + wordsFunction + `()
+{
+    COMPREPLY=( $(compgen -W "$*" -- "$cur") )
+}
+`
+
 var Cmd = &cobra.Command{
 	Use:   "completion",
 	Short: "Generates bash completion scripts",
@@ -38,8 +52,17 @@ To configure your bash shell to load completions for each session add to your ba
 	RunE: run,
 }
 
+// AddWords configures the completion of the value of the given flag so that it completes the given
+// words. The words can't contain white space. The flag must have already been added to the set.
+func AddWords(flags *pflag.FlagSet, name string, words ...string) {
+	handler := wordsFunction + " " + strings.Join(words, " ")
+	_ = flags.SetAnnotation(name, cobra.BashCompCustom, []string{handler})
+}
+
 func run(cmd *cobra.Command, argv []string) error {
-	err := cmd.Root().GenBashCompletion(os.Stdout)
+	root := cmd.Root()
+	root.BashCompletionFunction = bashFunctions
+	err := root.GenBashCompletion(os.Stdout)
 	if err != nil {
 		return fmt.Errorf("Unable to generate bash completions: %v", err)
 	}
@@ -19,8 +19,12 @@ package config
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/edit"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/get"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/migrate"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/set"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/setcredentials"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/setinsecure"
 )
 
 var Cmd = &cobra.Command{
@@ -29,6 +33,10 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(edit.Cmd)
 	Cmd.AddCommand(get.Cmd)
+	Cmd.AddCommand(migrate.Cmd)
 	Cmd.AddCommand(set.Cmd)
+	Cmd.AddCommand(setcredentials.Cmd)
+	Cmd.AddCommand(setinsecure.Cmd)
 }
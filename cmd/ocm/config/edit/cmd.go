@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the configuration file",
+	Long: "Open the configuration file in the editor given by the EDITOR or VISUAL " +
+		"environment variables, or in 'vi' ('notepad' in Windows) if none is set. When " +
+		"the editor exits the content is checked, and the file is only replaced if it is " +
+		"valid. If it isn't valid the editor can be opened again, otherwise the original " +
+		"file is preserved.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Read the current content of the configuration file:
+	file, err := config.Location()
+	if err != nil {
+		return fmt.Errorf("Can't get config file location: %v", err)
+	}
+	// #nosec G304
+	original, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("Config file '%s' doesn't exist, run the 'login' command first", file)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't read config file '%s': %v", file, err)
+	}
+
+	// Edit a copy of the file, so that the original isn't modified till the result has been
+	// checked:
+	temp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".edit*.json")
+	if err != nil {
+		return fmt.Errorf("Can't create temporary file: %v", err)
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(original)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Can't write temporary file '%s': %v", temp.Name(), err)
+	}
+
+	// Run the editor till the result is valid or the user gives up:
+	editor := selectEditor()
+	for {
+		err = runEditor(editor, temp.Name())
+		if err != nil {
+			return err
+		}
+		// #nosec G304
+		edited, err := ioutil.ReadFile(temp.Name())
+		if err != nil {
+			return fmt.Errorf("Can't read temporary file '%s': %v", temp.Name(), err)
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Fprintf(os.Stderr, "Config file '%s' hasn't changed\n", file)
+			return nil
+		}
+		err = config.CheckFile(edited)
+		if err == nil {
+			err = config.ReplaceFile(edited)
+			if err != nil {
+				return fmt.Errorf("Can't save config file '%s': %v", file, err)
+			}
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "The edited config file isn't valid: %v\n", err)
+		again, err := askAgain()
+		if err != nil {
+			return err
+		}
+		if !again {
+			return fmt.Errorf("Config file '%s' hasn't been changed", file)
+		}
+	}
+}
+
+// selectEditor returns the command line of the editor, taken from the EDITOR or VISUAL
+// environment variables, or the default editor of the operating system if they are empty.
+func selectEditor() []string {
+	for _, name := range []string{"EDITOR", "VISUAL"} {
+		fields := strings.Fields(os.Getenv(name))
+		if len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// runEditor runs the given editor command on the given file, connected to the terminal, and
+// waits till it exits.
+func runEditor(editor []string, file string) error {
+	// #nosec G204
+	command := exec.Command(editor[0], append(editor[1:], file)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err := command.Run()
+	if err != nil {
+		return fmt.Errorf("Can't run editor '%s': %v", strings.Join(editor, " "), err)
+	}
+	return nil
+}
+
+// askAgain asks the user if the editor should be opened again to fix the content of the file. It
+// returns false without asking when the standard input isn't a terminal.
+func askAgain() (again bool, err error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return
+	}
+	prompt := &survey.Confirm{
+		Message: "Edit again?",
+		Default: true,
+	}
+	err = survey.AskOne(prompt, &again, nil)
+	if err != nil {
+		err = fmt.Errorf("Can't ask for confirmation: %v", err)
+	}
+	return
+}
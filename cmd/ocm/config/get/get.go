@@ -30,10 +30,12 @@ var args struct {
 }
 
 var Cmd = &cobra.Command{
-	Use:   "get VARIABLE",
+	Use:   "get [VARIABLE]",
 	Short: "Prints the config variable",
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  run,
+	Long: "Prints the value of the given config variable. If no variable is given then " +
+		"all the variables are printed, with the secrets redacted.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: run,
 }
 
 func init() {
@@ -47,36 +49,29 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+		return err
 	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
+
+	// Print all the variables, without the secrets, if no variable has been given:
+	if len(argv) == 0 {
+		redacted := cfg.Redacted()
+		for _, key := range config.Keys() {
+			value, err := redacted.Get(key)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "%s: %s\n", key, value)
+		}
+		return nil
 	}
 
-	switch argv[0] {
-	case "access_token":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.AccessToken)
-	case "client_id":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.ClientID)
-	case "client_secret":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.ClientSecret)
-	case "insecure":
-		fmt.Fprintf(os.Stdout, "%v\n", cfg.Insecure)
-	case "password":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.Password)
-	case "refresh_token":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.RefreshToken)
-	case "scopes":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.Scopes)
-	case "token_url":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.TokenURL)
-	case "url":
-		fmt.Fprintf(os.Stdout, "%s\n", cfg.URL)
-	default:
-		fmt.Fprintf(os.Stderr, "Uknown setting\n")
+	value, err := cfg.Get(argv[0])
+	if err != nil {
+		return fmt.Errorf("Can't get config variable: %v", err)
 	}
+	fmt.Fprintf(os.Stdout, "%s\n", value)
 
 	return nil
 }
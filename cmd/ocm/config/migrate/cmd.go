@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite the configuration file in the current format",
+	Long: "Rewrite the configuration file in the current format. Files in older formats are " +
+		"always read correctly, and they are updated automatically the next time that they " +
+		"are saved, but they aren't modified just by reading them. This command updates the " +
+		"file explicitly.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Get the location of the configuration file:
+	file, err := config.Location()
+	if err != nil {
+		return fmt.Errorf("Can't get config file location: %v", err)
+	}
+
+	// Migrate the file:
+	migrated, err := config.Migrate()
+	if err != nil {
+		return fmt.Errorf("Can't migrate config file: %v", err)
+	}
+	if migrated {
+		fmt.Printf(
+			"Config file '%s' migrated to version %d\n",
+			file, config.CurrentVersion,
+		)
+	} else {
+		fmt.Printf(
+			"Config file '%s' doesn't need migration\n",
+			file,
+		)
+	}
+
+	return nil
+}
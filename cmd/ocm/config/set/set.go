@@ -18,7 +18,6 @@ package set
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -32,8 +31,10 @@ var args struct {
 var Cmd = &cobra.Command{
 	Use:   "set VARIABLE VALUE",
 	Short: "Sets the variable's value",
-	Args:  cobra.MinimumNArgs(2),
-	RunE:  run,
+	Long: "Sets the value of the given config variable. The value of 'scopes' is a comma " +
+		"separated list.",
+	Args: cobra.ExactArgs(2),
+	RunE: run,
 }
 
 func init() {
@@ -47,39 +48,14 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+		return err
 	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-	value := argv[1]
 
-	switch argv[0] {
-	case "access_token":
-		cfg.AccessToken = value
-	case "client_id":
-		cfg.ClientID = value
-	case "client_secret":
-		cfg.ClientSecret = value
-	case "insecure":
-		cfg.Insecure, err = strconv.ParseBool(value)
-		if err != nil {
-			return fmt.Errorf("Failed to set insecure: %v", value)
-		}
-	case "password":
-		cfg.Password = value
-	case "refresh_token":
-		cfg.RefreshToken = value
-	case "scopes":
-		return fmt.Errorf("Setting scopes is unsupported")
-	case "token_url":
-		cfg.TokenURL = value
-	case "url":
-		cfg.URL = value
-	default:
-		return fmt.Errorf("Unknown setting")
+	err = cfg.Set(argv[0], argv[1])
+	if err != nil {
+		return fmt.Errorf("Can't set config variable: %v", err)
 	}
 
 	err = config.Save(cfg)
@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setcredentials
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var args struct {
+	tokenURL   string
+	clientID   string
+	scopes     []string
+	addScopes  []string
+	url        string
+	token      string
+	insecure   bool
+	useKeyring bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "set-credentials --token TOKEN [--url URL]",
+	Short: "Store credentials in the configuration file",
+	Long: "Store credentials in the configuration file exactly like the 'login' command does, " +
+		"but without sending any request to verify them. This is intended for scripts " +
+		"that prepare the configuration, and running it again with the same options " +
+		"produces the same result.",
+	Example: " ocm config set-credentials --token $OFFLINE_ACCESS_TOKEN --url https://api.openshift.com",
+	Args:    cobra.NoArgs,
+	RunE:    run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.token,
+		"token",
+		"",
+		"Access or refresh token.",
+	)
+	flags.StringVar(
+		&args.url,
+		"url",
+		sdk.DefaultURL,
+		"URL of the API gateway.",
+	)
+	flags.StringVar(
+		&args.tokenURL,
+		"token-url",
+		"",
+		fmt.Sprintf(
+			"OpenID token URL. The default value is '%s'. Except when the token was "+
+				"issued by '%s'. In that case the default is '%s'.",
+			config.PreferredTokenURL, config.DeprecatedIssuer, config.DeprecatedTokenURL,
+		),
+	)
+	flags.StringVar(
+		&args.clientID,
+		"client-id",
+		"",
+		fmt.Sprintf(
+			"OpenID client identifier. The default value is '%s'. Except when the "+
+				"token was issued by '%s'. In that case the default is '%s'.",
+			config.PreferredClientID, config.DeprecatedIssuer, config.DeprecatedClientID,
+		),
+	)
+	flags.StringSliceVar(
+		&args.scopes,
+		"scope",
+		sdk.DefaultScopes,
+		"OpenID scope. If this option is used it will replace completely the default "+
+			"scopes. Can be repeated multiple times to specify multiple scopes.",
+	)
+	flags.StringSliceVar(
+		&args.addScopes,
+		"add-scope",
+		nil,
+		"OpenID scope to add to the default scopes. Can be repeated multiple times to "+
+			"add multiple scopes. If the '--scope' option is also used the scopes are "+
+			"added to the ones given with that option instead. Duplicated scopes are "+
+			"removed.",
+	)
+	flags.BoolVar(
+		&args.insecure,
+		"insecure",
+		false,
+		"Enables insecure communication with the server. This disables verification of TLS "+
+			"certificates and host names.",
+	)
+	flags.BoolVar(
+		&args.useKeyring,
+		"use-keyring",
+		false,
+		"Store the tokens, the password and the client secret in the keyring of the "+
+			"operating system instead of in the configuration file. If the keyring isn't "+
+			"available they will be stored in the configuration file, with a warning.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check mandatory options:
+	if args.token == "" {
+		return fmt.Errorf("Option '--token' is mandatory")
+	}
+	if args.url == "" {
+		return fmt.Errorf("Option '--url' is mandatory")
+	}
+
+	// Check that the token can be parsed and that it hasn't expired:
+	token, err := config.ParseToken(args.token)
+	if err != nil {
+		return fmt.Errorf("Can't parse token '%s': %v", args.token, err)
+	}
+	expiry, err := config.TokenExpiry(token)
+	if err != nil {
+		return fmt.Errorf("Can't extract expiry time from 'exp' claim: %v", err)
+	}
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		return fmt.Errorf(
+			"The provided token expired at %s, get a new one and try again",
+			expiry.Local().Format(time.RFC1123),
+		)
+	}
+
+	// Apply the default OpenID details if not explicitly provided by the user:
+	tokenURL, clientID, err := config.TokenDefaults(token)
+	if err != nil {
+		return fmt.Errorf("Can't select OpenID details: %v", err)
+	}
+	if args.tokenURL != "" {
+		tokenURL = args.tokenURL
+	}
+	if args.clientID != "" {
+		clientID = args.clientID
+	}
+
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		cfg = new(config.Config)
+	}
+
+	// Update the configuration replacing all the credentials, so that the result doesn't depend
+	// on what was stored before:
+	cfg.TokenURL = tokenURL
+	cfg.ClientID = clientID
+	cfg.ClientSecret = ""
+	cfg.Scopes = config.MergeScopes(args.scopes, args.addScopes)
+	cfg.URL = args.url
+	cfg.User = ""
+	cfg.Password = ""
+	cfg.SetInsecure(args.insecure)
+	cfg.Keyring = args.useKeyring
+	cfg.AccessToken = ""
+	cfg.RefreshToken = ""
+	err = cfg.SetToken(args.token, token)
+	if err != nil {
+		return fmt.Errorf("Can't use token: %v", err)
+	}
+
+	// Save the configuration:
+	err = config.Save(cfg)
+	if err != nil {
+		return fmt.Errorf("Can't save config file: %v", err)
+	}
+
+	return nil
+}
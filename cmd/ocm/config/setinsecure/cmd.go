@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setinsecure
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var args struct {
+	yes bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "set-insecure true|false",
+	Short: "Enable or disable insecure communication with the server",
+	Long: "Enable or disable insecure communication with the server. When it is enabled " +
+		"the verification of TLS certificates and host names is disabled, and the time is " +
+		"recorded in the configuration file.",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.yes,
+		"yes",
+		false,
+		"Don't ask for confirmation when enabling insecure communication.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	insecure, err := strconv.ParseBool(argv[0])
+	if err != nil {
+		return fmt.Errorf("Value '%s' isn't valid, use 'true' or 'false'", argv[0])
+	}
+
+	// Load the configuration file:
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return err
+	}
+
+	// Warn the user and ask for confirmation when enabling insecure communication:
+	if insecure && !cfg.Insecure {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: Insecure communication disables verification of TLS certificates "+
+				"and host names of '%s'. This makes it possible for others to "+
+				"intercept the credentials and the data sent to the server. Disable it "+
+				"with 'ocm config set-insecure false' as soon as it isn't needed.\n",
+			cfg.URL,
+		)
+		if !args.yes {
+			confirmed := false
+			prompt := &survey.Confirm{
+				Message: "Enable insecure communication?",
+			}
+			err = survey.AskOne(prompt, &confirmed, nil)
+			if err != nil {
+				return fmt.Errorf("Can't ask for confirmation: %v", err)
+			}
+			if !confirmed {
+				return nil
+			}
+		}
+	}
+
+	// Update the configuration:
+	cfg.SetInsecure(insecure)
+	err = config.Save(cfg)
+	if err != nil {
+		return fmt.Errorf("Can't save config file: %v", err)
+	}
+	if cfg.InsecureSince != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Insecure communication enabled since %s\n",
+			cfg.InsecureSince.Local().Format(time.RFC1123),
+		)
+	}
+
+	return nil
+}
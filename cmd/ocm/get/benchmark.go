@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the '--benchmark' option of the 'get' command.
+
+package get
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/flags"
+)
+
+// benchmarkResult contains the outcome of one of the requests sent by the benchmark.
+type benchmarkResult struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// benchmarkGet sends the GET request for the given path the number of times given in the
+// '--benchmark' option, using the number of parallel workers given in the '--concurrency' option.
+// The response bodies are discarded, and the latency statistics are written to the given stream.
+// It returns the number of requests that failed.
+func benchmarkGet(stream io.Writer, connection *sdk.Connection, path string) (failed int) {
+	// Send the requests:
+	results := make([]benchmarkResult, args.benchmark)
+	next := make(chan int)
+	var wait sync.WaitGroup
+	for i := 0; i < args.concurrency; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range next {
+				results[index] = benchmarkRequest(connection, path)
+			}
+		}()
+	}
+	start := time.Now()
+	for i := 0; i < args.benchmark; i++ {
+		next <- i
+	}
+	close(next)
+	wait.Wait()
+	total := time.Since(start)
+
+	// Count the failures and sort the latencies of the requests that were sent:
+	var latencies []time.Duration
+	errors := 0
+	statuses := map[int]int{}
+	for _, result := range results {
+		if result.err != nil {
+			errors++
+			continue
+		}
+		latencies = append(latencies, result.latency)
+		statuses[result.status]++
+		if result.status >= 400 {
+			failed++
+		}
+	}
+	failed += errors
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	// Print the statistics:
+	fmt.Fprintf(stream, "Requests:    %d\n", args.benchmark)
+	fmt.Fprintf(stream, "Concurrency: %d\n", args.concurrency)
+	fmt.Fprintf(stream, "Total time:  %v\n", total.Round(time.Millisecond))
+	fmt.Fprintf(stream, "Errors:      %d\n", errors)
+	codes := make([]int, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(stream, "Status %d:  %d\n", code, statuses[code])
+	}
+	if len(latencies) > 0 {
+		fmt.Fprintf(stream, "Latency:\n")
+		fmt.Fprintf(stream, "  min: %v\n", latencies[0].Round(time.Microsecond))
+		fmt.Fprintf(stream, "  p50: %v\n", percentile(latencies, 50).Round(time.Microsecond))
+		fmt.Fprintf(stream, "  p90: %v\n", percentile(latencies, 90).Round(time.Microsecond))
+		fmt.Fprintf(stream, "  p99: %v\n", percentile(latencies, 99).Round(time.Microsecond))
+		fmt.Fprintf(stream, "  max: %v\n", latencies[len(latencies)-1].Round(time.Microsecond))
+	}
+
+	return
+}
+
+// benchmarkRequest sends one GET request and measures the time until the complete response has
+// been received.
+func benchmarkRequest(connection *sdk.Connection, path string) (result benchmarkResult) {
+	request := connection.Get().Path(path)
+	flags.ApplyParameterFlag(request, args.parameter)
+	flags.ApplyHeaderFlag(request, args.header)
+	start := time.Now()
+	ctx, cancel := config.RequestContext(connection)
+	response, err := request.SendContext(ctx)
+	cancel()
+	result.latency = time.Since(start)
+	if err != nil {
+		result.err = err
+		return
+	}
+	result.status = response.Status()
+	return
+}
+
+// percentile returns the given percentile of the sorted list of latencies, using the nearest rank
+// method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -17,21 +17,36 @@ limitations under the License.
 package get
 
 import (
+	"bytes"
 	"fmt"
+	"mime"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
+	"github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/operation"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
 var args struct {
-	parameter []string
-	header    []string
-	single    bool
+	parameter    []string
+	header       []string
+	single       bool
+	head         bool
+	watch        bool
+	interval     time.Duration
+	diff         bool
+	watchTimeout time.Duration
+	benchmark    int
+	concurrency  int
 }
 
 var Cmd = &cobra.Command{
@@ -51,6 +66,54 @@ func init() {
 		false,
 		"Return the output as a single line.",
 	)
+	fs.BoolVar(
+		&args.head,
+		"head",
+		false,
+		"Send a HEAD request instead of a GET request, and print the response status and "+
+			"headers instead of the body.",
+	)
+	fs.BoolVar(
+		&args.watch,
+		"watch",
+		false,
+		"Send the request repeatedly and print the response body each time that it "+
+			"changes, until the command is interrupted, the server responds with an "+
+			"error or the time given in the '--watch-timeout' option expires.",
+	)
+	fs.DurationVar(
+		&args.interval,
+		"interval",
+		5*time.Second,
+		"Time to wait between requests when the '--watch' option is used.",
+	)
+	fs.BoolVar(
+		&args.diff,
+		"diff",
+		false,
+		"When the '--watch' option is used print only the fields that have changed since "+
+			"the previous response, instead of the complete body.",
+	)
+	fs.DurationVar(
+		&args.watchTimeout,
+		"watch-timeout",
+		0,
+		"Maximum time to keep sending the request when the '--watch' option is used, for "+
+			"example '10m'. Zero means no limit.",
+	)
+	fs.IntVar(
+		&args.benchmark,
+		"benchmark",
+		0,
+		"Send the request this number of times, discarding the response bodies, and print "+
+			"the latency statistics and the number of errors.",
+	)
+	fs.IntVar(
+		&args.concurrency,
+		"concurrency",
+		1,
+		"Number of requests sent in parallel when the '--benchmark' option is used.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -58,74 +121,193 @@ func run(cmd *cobra.Command, argv []string) error {
 	if err != nil {
 		return fmt.Errorf("Could not create URI: %v", err)
 	}
+	if args.head && args.watch {
+		return fmt.Errorf("Options '--head' and '--watch' are mutually exclusive")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Option '--interval' must be positive")
+	}
+	if args.watchTimeout < 0 {
+		return fmt.Errorf("Option '--watch-timeout' can't be negative")
+	}
+	if (args.diff || args.watchTimeout > 0) && !args.watch {
+		return fmt.Errorf("Options '--diff' and '--watch-timeout' can only be used with '--watch'")
+	}
+	if args.benchmark < 0 {
+		return fmt.Errorf("Option '--benchmark' can't be negative")
+	}
+	if args.benchmark > 0 && (args.head || args.watch) {
+		return fmt.Errorf("Option '--benchmark' can't be used with '--head' or '--watch'")
+	}
+	if args.concurrency < 1 {
+		return fmt.Errorf("Option '--concurrency' must be positive")
+	}
 
 	// Load the configuration file:
-	cfg, err := config.Load()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
+		return err
 	}
 
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, err := cfg.Armed()
+	// Create the connection:
+	connection, err := ocm.ConnectionFor(cfg)
 	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
+		return err
 	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+
+	// Send a HEAD request instead of a GET, or repeat the request, if requested:
+	var status int
+	failed := false
+	switch {
+	case args.head:
+		status, err = sendHead(os.Stdout, connection, path)
+		if err != nil {
+			return fmt.Errorf("Can't send request: %v", err)
+		}
+	case args.benchmark > 0:
+		failed = benchmarkGet(os.Stdout, connection, path) > 0
+	case args.watch:
+		status, err = watchGet(connection, path)
+		if err != nil {
+			return err
+		}
+	default:
+		response, err := sendGet(connection, path)
+		if err != nil {
+			return err
+		}
+		status = response.Status()
+		err = printBody(response)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Create the connection:
-	connection, err := cfg.Connection()
-	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+	// Save the configuration, unless the URL has been overridden:
+	if config.URLOverride() == "" {
+		ctx, cancel := config.RequestContext(connection)
+		cfg.AccessToken, cfg.RefreshToken, err = connection.TokensContext(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("Can't get tokens: %v", err)
+		}
+		err = config.Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
+	}
+
+	// Bye:
+	if failed || status >= 400 {
+		os.Exit(1)
 	}
 
+	return nil
+}
+
+// sendGet sends the GET request for the given path.
+func sendGet(connection *sdk.Connection, path string) (response *sdk.Response, err error) {
 	// Create and populate the request:
 	request := connection.Get().Path(path)
 	flags.ApplyParameterFlag(request, args.parameter)
 	flags.ApplyHeaderFlag(request, args.header)
 
 	// Send the request:
-	response, err := request.Send()
+	ctx, cancel := config.RequestContext(connection)
+	response, err = request.SendContext(ctx)
+	cancel()
 	if err != nil {
-		return fmt.Errorf("Can't send request: %v", err)
+		err = fmt.Errorf("Can't send request: %v", err)
+		return
+	}
+	operation.Print(response.Header(operation.Header))
+	return
+}
+
+// printBody prints the body of the given response, to the standard output stream if it was
+// successful or to the standard error stream if it wasn't. The body is only formatted when the
+// server says that it is JSON, other content types are printed unchanged.
+func printBody(response *sdk.Response) error {
+	var err error
+	stream := os.Stdout
+	if response.Status() >= 400 {
+		stream = os.Stderr
 	}
-	status := response.Status()
 	body := response.Bytes()
-	if status < 400 {
-		if args.single {
-			err = dump.Simple(os.Stdout, body)
-		} else {
-			err = dump.Pretty(os.Stdout, body)
-		}
-	} else {
-		if args.single {
-			err = dump.Simple(os.Stderr, body)
-		} else {
-			err = dump.Pretty(os.Stderr, body)
-		}
+	switch {
+	case !isJSON(response.Header("Content-Type")):
+		_, err = stream.Write(body)
+	case args.single:
+		err = dump.Simple(stream, body)
+	default:
+		err = dump.Pretty(stream, body)
 	}
 	if err != nil {
 		return fmt.Errorf("Can't print body: %v", err)
 	}
+	return nil
+}
 
-	// Save the configuration:
-	cfg.AccessToken, cfg.RefreshToken, err = connection.Tokens()
-	if err != nil {
-		return fmt.Errorf("Can't get tokens: %v", err)
+// isJSON checks if the given content type corresponds to a JSON document. An empty or invalid
+// content type is also considered JSON, as that is what the API usually returns.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
 	}
-	err = config.Save(cfg)
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return fmt.Errorf("Can't save config file: %v", err)
+		return true
 	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
 
-	// Bye:
-	if status >= 400 {
-		os.Exit(1)
+// watchGet sends the GET request repeatedly, waiting the time given in the '--interval' option
+// between requests, and prints the response body each time that it changes, or only the changed
+// fields if the '--diff' option is used. It stops when the server responds with an error, when the
+// process is interrupted or when the time given in the '--watch-timeout' option expires.
+func watchGet(connection *sdk.Connection, path string) (status int, err error) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	var deadline <-chan time.Time
+	if args.watchTimeout > 0 {
+		deadline = time.After(args.watchTimeout)
+	}
+	var previous []byte
+	first := true
+	for {
+		var response *sdk.Response
+		response, err = sendGet(connection, path)
+		if err != nil {
+			return
+		}
+		status = response.Status()
+		body := response.Bytes()
+		if first || !bytes.Equal(body, previous) {
+			if !first {
+				fmt.Fprintf(os.Stdout, "\n")
+			}
+			if args.diff && !first && status < 400 &&
+				isJSON(response.Header("Content-Type")) {
+				err = dump.Diff(os.Stdout, previous, body)
+			} else {
+				err = printBody(response)
+			}
+			if err != nil {
+				return
+			}
+			previous = body
+			first = false
+		}
+		if status >= 400 {
+			return
+		}
+		select {
+		case <-interrupt:
+			return
+		case <-deadline:
+			return
+		case <-time.After(args.interval):
+		}
 	}
-
-	return nil
 }
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the '--head' option of the 'get' command.
+
+package get
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/glog"
+	"github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/debug"
+	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/operation"
+)
+
+// headHeaders are the response headers that are printed after the status line.
+var headHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"ETag",
+	"Last-Modified",
+}
+
+// headRequest collects the query parameters and headers of a HEAD request. It has the same
+// 'Parameter' and 'Header' methods than the SDK requests, so that the shared command line flags
+// can be applied to it.
+type headRequest struct {
+	query  url.Values
+	header http.Header
+}
+
+// Parameter adds a query parameter to the request.
+func (r *headRequest) Parameter(name string, value string) {
+	r.query.Add(name, value)
+}
+
+// Header adds a header to the request.
+func (r *headRequest) Header(name string, value string) {
+	r.header.Add(name, value)
+}
+
+// sendHead sends a HEAD request for the given path and writes the status and the relevant
+// response headers to the given stream. The SDK connection only supports the GET, POST, PATCH and
+// DELETE methods, so the request is sent outside of the SDK, with its own HTTP client. It reuses
+// the URL, TLS settings and access token of the connection, and the context returned by
+// config.RequestContext, so that the timeout applies, but the SDK debug log isn't written and a
+// short summary is written instead when the debug mode is enabled.
+func sendHead(stream io.Writer, connection *sdk.Connection, path string) (status int, err error) {
+	// Prepare the request:
+	request := &headRequest{
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	flags.ApplyParameterFlag(request, args.parameter)
+	flags.ApplyHeaderFlag(request, args.header)
+	base, err := url.Parse(connection.URL())
+	if err != nil {
+		err = fmt.Errorf("can't parse API URL '%s': %v", connection.URL(), err)
+		return
+	}
+	address := base.ResolveReference(&url.URL{
+		Path:     path,
+		RawQuery: request.query.Encode(),
+	})
+	httpRequest, err := http.NewRequest(http.MethodHead, address.String(), nil)
+	if err != nil {
+		return
+	}
+	ctx, cancel := config.RequestContext(connection)
+	defer cancel()
+	httpRequest = httpRequest.WithContext(ctx)
+	httpRequest.Header = request.header
+	accessToken, _, err := connection.TokensContext(ctx)
+	if err != nil {
+		err = fmt.Errorf("can't get access token: %v", err)
+		return
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+accessToken)
+	httpRequest.Header.Set("User-Agent", connection.Agent())
+	httpRequest.Header.Set("Accept", "application/json")
+
+	// Send the request using the same TLS settings than the connection:
+	// #nosec G402
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: connection.Insecure(),
+				RootCAs:            connection.TrustedCAs(),
+			},
+		},
+	}
+	if debug.Enabled() {
+		glog.Infof("Sending HEAD request to '%s' outside of the SDK", address)
+	}
+	response, err := client.Do(httpRequest)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	if debug.Enabled() {
+		glog.Infof("Response status is '%s'", response.Status)
+	}
+	operation.Print(response.Header.Get(operation.Header))
+
+	// Print the status and the headers:
+	status = response.StatusCode
+	fmt.Fprintf(stream, "Status: %s\n", response.Status)
+	for _, name := range headHeaders {
+		value := response.Header.Get(name)
+		if value != "" {
+			fmt.Fprintf(stream, "%s: %s\n", name, value)
+		}
+	}
+
+	return
+}
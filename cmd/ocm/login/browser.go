@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the '--browser' option of the 'login' command, which
+// uses the OpenID authorization code flow with PKCE.
+
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/browser"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+// Range of local ports where the listener that receives the authorization code is started. A
+// random port of the range is tried first, and then other random ports if that fails.
+const (
+	browserMinPort  = 49152
+	browserMaxPort  = 65535
+	browserAttempts = 10
+)
+
+// browserTimeout is the maximum time to wait for the user to complete the authentication in the
+// browser.
+const browserTimeout = 5 * time.Minute
+
+// browserCallback is the result received in the redirect URI.
+type browserCallback struct {
+	code string
+	err  error
+}
+
+// browserLogin authenticates the user with the default browser, using the authorization code
+// flow with PKCE, so that no client secret is needed. It returns the access and refresh tokens.
+// The insecure flag and the CA file are used to verify the certificate of the token endpoint.
+func browserLogin(tokenURL string, clientID string, scopes []string, insecure bool,
+	caFile string) (accessToken string, refreshToken string, err error) {
+	// The authorization endpoint has the same location than the token endpoint, but replacing
+	// the last segment of the path:
+	authURL, err := authorizationURL(tokenURL)
+	if err != nil {
+		return
+	}
+
+	// Generate the PKCE verifier and challenge, and the state used to check that the redirect
+	// corresponds to this request:
+	verifier, err := randomText()
+	if err != nil {
+		return
+	}
+	digest := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(digest[:])
+	state, err := randomText()
+	if err != nil {
+		return
+	}
+
+	// Start the listener that will receive the redirect:
+	listener, err := browserListen()
+	if err != nil {
+		return
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+	results := make(chan browserCallback, 1)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			result := parseCallback(r.URL.Query(), state)
+			if result.err != nil {
+				fmt.Fprintf(w, "Authentication failed: %v\n", result.err)
+			} else {
+				fmt.Fprintf(w, "Authentication completed, you can close this window.\n")
+			}
+			select {
+			case results <- result:
+			default:
+			}
+		}),
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+	}()
+
+	// Open the browser, or ask the user to open it:
+	query := url.Values{}
+	query.Set("client_id", clientID)
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+	address := authURL + "?" + query.Encode()
+	err = browser.OpenURL(address)
+	if err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Can't open the browser: %v\nOpen the following URL to log in:\n\n%s\n\n",
+			err, address,
+		)
+	} else {
+		fmt.Fprintf(os.Stderr, "Complete the authentication in the browser.\n")
+	}
+
+	// Wait for the authorization code:
+	var result browserCallback
+	select {
+	case result = <-results:
+	case <-time.After(browserTimeout):
+		err = fmt.Errorf("authentication wasn't completed in %v", browserTimeout)
+		return
+	}
+	if result.err != nil {
+		err = result.err
+		return
+	}
+
+	// Exchange the authorization code for the tokens:
+	accessToken, refreshToken, err = exchangeCode(
+		tokenURL, clientID, result.code, redirectURI, verifier, insecure, caFile,
+	)
+	return
+}
+
+// authorizationURL calculates the URL of the authorization endpoint from the URL of the token
+// endpoint.
+func authorizationURL(tokenURL string) (result string, err error) {
+	parsed, err := url.Parse(tokenURL)
+	if err != nil {
+		err = fmt.Errorf("can't parse token URL '%s': %v", tokenURL, err)
+		return
+	}
+	if !strings.HasSuffix(parsed.Path, "/token") {
+		err = fmt.Errorf(
+			"can't calculate authorization URL from token URL '%s', the path should "+
+				"end with '/token'",
+			tokenURL,
+		)
+		return
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/token") + "/auth"
+	result = parsed.String()
+	return
+}
+
+// browserListen starts listening in a random local port, trying other ports if that fails.
+func browserListen() (listener net.Listener, err error) {
+	for i := 0; i < browserAttempts; i++ {
+		var offset *big.Int
+		offset, err = rand.Int(rand.Reader, big.NewInt(browserMaxPort-browserMinPort+1))
+		if err != nil {
+			return
+		}
+		address := fmt.Sprintf("127.0.0.1:%d", browserMinPort+offset.Int64())
+		listener, err = net.Listen("tcp", address)
+		if err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("can't listen in any local port after %d attempts: %v", browserAttempts, err)
+	return
+}
+
+// parseCallback extracts the authorization code from the query parameters of the redirect,
+// checking that the state is the expected one.
+func parseCallback(query url.Values, state string) (result browserCallback) {
+	if query.Get("state") != state {
+		result.err = fmt.Errorf("state of the redirect doesn't match the request")
+		return
+	}
+	if query.Get("error") != "" {
+		result.err = fmt.Errorf(
+			"authentication server returned error '%s': %s",
+			query.Get("error"), query.Get("error_description"),
+		)
+		return
+	}
+	result.code = query.Get("code")
+	if result.code == "" {
+		result.err = fmt.Errorf("redirect doesn't contain the authorization code")
+	}
+	return
+}
+
+// exchangeCode sends the authorization code to the token endpoint and returns the access and
+// refresh tokens.
+func exchangeCode(tokenURL, clientID, code, redirectURI, verifier string, insecure bool,
+	caFile string) (accessToken string, refreshToken string, err error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("client_id", clientID)
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	// Use the same certificate authorities that the connection will use:
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+	if caFile != "" && !insecure {
+		tlsConfig.RootCAs, err = config.LoadCAs(caFile)
+		if err != nil {
+			return
+		}
+	}
+	client := &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	response, err := client.PostForm(tokenURL, form)
+	if err != nil {
+		err = fmt.Errorf("can't send token request: %v", err)
+		return
+	}
+	defer response.Body.Close()
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&body)
+	if err != nil {
+		err = fmt.Errorf("can't parse token response: %v", err)
+		return
+	}
+	if body.Error != "" {
+		err = fmt.Errorf("token request failed with error '%s': %s", body.Error,
+			body.ErrorDescription)
+		return
+	}
+	if response.StatusCode != http.StatusOK || body.AccessToken == "" {
+		err = fmt.Errorf("token request failed with status %d", response.StatusCode)
+		return
+	}
+	accessToken = body.AccessToken
+	refreshToken = body.RefreshToken
+	return
+}
+
+// randomText generates a random text suitable for the PKCE verifier and the state.
+func randomText() (text string, err error) {
+	data := make([]byte, 32)
+	_, err = rand.Read(data)
+	if err != nil {
+		err = fmt.Errorf("can't generate random text: %v", err)
+		return
+	}
+	text = base64.RawURLEncoding.EncodeToString(data)
+	return
+}
@@ -17,47 +17,106 @@ limitations under the License.
 package login
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/golang/glog"
+	"github.com/mattn/go-isatty"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/completion"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/debug"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/shell"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
-// Preferred OpenID details:
-const (
-	// #nosec G101
-	preferredTokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
-	preferredClientID = "cloud-services"
-)
+var args struct {
+	tokenURL          string
+	clientID          string
+	clientSecret      string
+	scopes            []string
+	addScopes         []string
+	url               string
+	token             string
+	user              string
+	password          string
+	passwordFile      string
+	tokenFile         string
+	insecure          bool
+	useKeyring        bool
+	checkConnectivity bool
+	caFile            string
+	persistent        bool
+	yes               bool
+	force             bool
+	storedOnly        bool
+	printConfig       bool
+	dryRun            bool
+	maxTokenAge       time.Duration
+	retries           int
+	retryDelay        time.Duration
+	browser           bool
+	toEnv             bool
+	format            string
+	status            bool
+}
 
-// Deprecated OpenID details used only when trying to authenticate with a user name and a password
-// or with a token issued by the deprecated OpenID server:
+// Names of the environment variables that are used when the corresponding command line options
+// aren't given. This is intended for automation, where secrets given in the command line would be
+// visible to other users in the list of processes.
 const (
-	// #nosec G101
-	deprecatedTokenURL = "https://developers.redhat.com/auth/realms/rhd/protocol/openid-connect/token"
-	deprecatedClientID = "ocm"
-	deprecatedIssuer   = "developers.redhat.com"
+	clientIDEnv     = "OCM_CLIENT_ID"
+	clientSecretEnv = "OCM_CLIENT_SECRET"
+	tokenEnv        = "OCM_TOKEN"
 )
 
-var args struct {
-	tokenURL     string
-	clientID     string
-	clientSecret string
-	scopes       []string
-	url          string
-	token        string
-	user         string
-	password     string
-	insecure     bool
-	persistent   bool
+// confirmPersistentEnv is the name of the environment variable that confirms that the user name
+// and password can be stored in clear text when the '--persistent' option is used and the
+// standard input isn't a terminal.
+const confirmPersistentEnv = "OCM_CONFIRM_PERSISTENT"
+
+// urlAliases contains the URLs of the API gateways of the well known environments, so that users
+// can write '--url staging' instead of the complete URL.
+var urlAliases = map[string]string{
+	"integration": "https://api-integration.6943.hive-integration.openshiftapps.com",
+	"production":  "https://api.openshift.com",
+	"staging":     "https://api.stage.openshift.com",
 }
 
+// Codes of the errors returned by the command. These are reported when the '--output json' option
+// is used, and shouldn't change, as programs may depend on them.
+const (
+	codeAuthenticationFailed = "LOGIN-AUTHENTICATION-FAILED"
+	codeConfig               = "LOGIN-CONFIG"
+	codeConflictingOptions   = "LOGIN-CONFLICTING-OPTIONS"
+	codeConnection           = "LOGIN-CONNECTION"
+	codeUnreachable          = "LOGIN-UNREACHABLE"
+	codeExpiredToken         = "LOGIN-EXPIRED-TOKEN"
+	codeInvalidConfig        = "LOGIN-INVALID-CONFIG"
+	codeInvalidOption        = "LOGIN-INVALID-OPTION"
+	codeInvalidToken         = "LOGIN-INVALID-TOKEN"
+	codeMissingCredentials   = "LOGIN-MISSING-CREDENTIALS"
+	codeMissingOption        = "LOGIN-MISSING-OPTION"
+	codeMissingURL           = "LOGIN-MISSING-URL"
+	codeNotConfirmed         = "LOGIN-NOT-CONFIRMED"
+	codeNotLoggedIn          = "LOGIN-NOT-LOGGED-IN"
+	codeOutput               = "LOGIN-OUTPUT"
+)
+
 var Cmd = &cobra.Command{
 	Use:   "login",
 	Short: "Log in",
@@ -74,8 +133,9 @@ func init() {
 		fmt.Sprintf(
 			"OpenID token URL. The default value is '%s'. Except when authenticating "+
 				"with a user name and password or with a token issued by '%s'. "+
-				"In that case the default is '%s'.",
-			preferredTokenURL, deprecatedIssuer, deprecatedTokenURL,
+				"In that case the default is '%s'. The value used in the previous "+
+				"login is kept if it was for the same server.",
+			config.PreferredTokenURL, config.DeprecatedIssuer, config.DeprecatedTokenURL,
 		),
 	)
 	flags.StringVar(
@@ -85,34 +145,64 @@ func init() {
 		fmt.Sprintf(
 			"OpenID client identifier. The default value is '%s'. Except when "+
 				"authenticating with a user name and password or with a token "+
-				"issued by '%s'. In that case the default is '%s'.",
-			preferredClientID, deprecatedIssuer, deprecatedClientID,
+				"issued by '%s'. In that case the default is '%s'. If not given "+
+				"the value of the '%s' environment variable will be used, and then "+
+				"the value used in the previous login if it was for the same server.",
+			config.PreferredClientID, config.DeprecatedIssuer, config.DeprecatedClientID,
+			clientIDEnv,
 		),
 	)
 	flags.StringVar(
 		&args.clientSecret,
 		"client-secret",
 		"",
-		"OpenID client secret.",
+		"OpenID client secret. If not given the value of the '"+clientSecretEnv+"' "+
+			"environment variable will be used. If the client identifier is given "+
+			"without the secret, and the standard input is a terminal, the secret will "+
+			"be requested interactively.",
 	)
 	flags.StringSliceVar(
 		&args.scopes,
 		"scope",
 		sdk.DefaultScopes,
 		"OpenID scope. If this option is used it will replace completely the default "+
-			"scopes. Can be repeated multiple times to specify multiple scopes.",
+			"scopes. Can be repeated multiple times to specify multiple scopes. If not "+
+			"given the scopes used in the previous login are kept if it was for the same "+
+			"server and with the same kind of credentials.",
+	)
+	flags.StringSliceVar(
+		&args.addScopes,
+		"add-scope",
+		nil,
+		"OpenID scope to add to the default scopes. Can be repeated multiple times to "+
+			"add multiple scopes. If the '--scope' option is also used the scopes are "+
+			"added to the ones given with that option instead. Duplicated scopes are "+
+			"removed.",
 	)
 	flags.StringVar(
 		&args.url,
 		"url",
 		sdk.DefaultURL,
-		"URL of the API gateway.",
+		fmt.Sprintf(
+			"URL of the API gateway. The value can be the complete URL or one of the "+
+				"aliases of the well known environments: %s.",
+			aliasesText(),
+		),
 	)
 	flags.StringVar(
 		&args.token,
 		"token",
 		"",
-		"Access or refresh token.",
+		"Access or refresh token. If not given the value of the '"+tokenEnv+"' "+
+			"environment variable will be used. Use '-' to read it from the standard "+
+			"input.",
+	)
+	flags.StringVar(
+		&args.tokenFile,
+		"token-file",
+		"",
+		"File containing the access or refresh token. Use '-' to read it from the "+
+			"standard input. The file must not be readable by other users.",
 	)
 	flags.StringVar(
 		&args.user,
@@ -124,7 +214,16 @@ func init() {
 		&args.password,
 		"password",
 		"",
-		"User password.",
+		"User password. If the user name is given without this option, and the standard "+
+			"input is a terminal, the password will be requested interactively. Use '-' "+
+			"to read it from the standard input.",
+	)
+	flags.StringVar(
+		&args.passwordFile,
+		"password-file",
+		"",
+		"File containing the user password. Use '-' to read it from the standard input. "+
+			"The file must not be readable by other users.",
 	)
 	flags.BoolVar(
 		&args.insecure,
@@ -133,6 +232,31 @@ func init() {
 		"Enables insecure communication with the server. This disables verification of TLS "+
 			"certificates and host names.",
 	)
+	flags.BoolVar(
+		&args.checkConnectivity,
+		"check-connectivity",
+		false,
+		"Before sending any credentials, check that the host of the API gateway can be "+
+			"resolved and that a connection can be established with it, reporting "+
+			"problems with the name, the connection and the TLS handshake separately.",
+	)
+	flags.BoolVar(
+		&args.useKeyring,
+		"use-keyring",
+		false,
+		"Store the tokens, the password and the client secret in the keyring of the "+
+			"operating system instead of in the configuration file. If the keyring isn't "+
+			"available they will be stored in the configuration file, with a warning.",
+	)
+	flags.StringVar(
+		&args.caFile,
+		"ca-file",
+		"",
+		"File containing additional certificate authorities, in PEM format, that will be "+
+			"trusted when verifying the TLS certificates of the servers. The file is "+
+			"read again each time that a command connects, so it can be updated "+
+			"without logging in again.",
+	)
 	flags.BoolVar(
 		&args.persistent,
 		"persistent",
@@ -142,23 +266,152 @@ func init() {
 			"this option is provided then the user name and password will be stored "+
 			"persistently, in clear text, which is potentially unsafe.",
 	)
+	flags.BoolVar(
+		&args.yes,
+		"yes",
+		false,
+		"Don't ask for confirmation before storing the user name and password in clear "+
+			"text when the '--persistent' option is used. This is required when the "+
+			"standard input isn't a terminal, unless the '"+confirmPersistentEnv+"' "+
+			"environment variable is set to 'true'.",
+	)
+	flags.BoolVar(
+		&args.force,
+		"force",
+		false,
+		"Don't ask for confirmation before replacing the credentials stored for a different "+
+			"server. This is required when the standard input isn't a terminal and the "+
+			"value of the '--url' option isn't the URL of the stored configuration.",
+	)
+	flags.BoolVar(
+		&args.storedOnly,
+		"stored-credentials-only",
+		false,
+		"Only store the credentials in the configuration file, without contacting the "+
+			"OpenID server to verify them. Tokens are still parsed and rejected if they "+
+			"have expired. This is intended for preparing configuration files in "+
+			"environments that can't reach the server. The '--offline' option is an "+
+			"alias of this one.",
+	)
+	flags.SetNormalizeFunc(normalizeFlag)
+	flags.BoolVar(
+		&args.printConfig,
+		"print-config",
+		false,
+		"Print the configuration that would be saved, with the secrets redacted, instead "+
+			"of saving it. Combine with '--stored-credentials-only' to also skip the "+
+			"verification of the credentials.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Verify the credentials, but don't save them in the configuration file. If they "+
+			"are valid 'credentials valid' will be printed, otherwise the command will "+
+			"fail. This is intended for checking credentials in scripts.",
+	)
+	flags.DurationVar(
+		&args.maxTokenAge,
+		"max-token-age",
+		90*24*time.Hour,
+		"Warn if the refresh or offline token given with '--token' was issued longer ago "+
+			"than this. The authentication server may stop accepting old tokens before "+
+			"they expire. Use zero to disable the warning.",
+	)
+	flags.IntVar(
+		&args.retries,
+		"retries",
+		3,
+		"Number of times to retry the request to the OpenID server when it fails because of "+
+			"a network error or a server error. Other errors, like rejected credentials, "+
+			"aren't retried.",
+	)
+	flags.DurationVar(
+		&args.retryDelay,
+		"retry-max-delay",
+		30*time.Second,
+		"Maximum delay between retries. The delay starts with one second and is doubled "+
+			"after each retry.",
+	)
+	flags.BoolVar(
+		&args.browser,
+		"browser",
+		false,
+		"Log in with the default browser, using the OpenID authorization code flow. If "+
+			"the browser can't be opened the URL will be printed instead.",
+	)
+	flags.BoolVar(
+		&args.toEnv,
+		"to-env",
+		false,
+		"Instead of saving the configuration file, print shell statements that export "+
+			"the URL and the token, suitable for 'eval'. The credentials are verified "+
+			"first. The password and the client secret are never printed.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		shell.FormatSh,
+		fmt.Sprintf(
+			"Shell syntax used by '--to-env'. Valid values are '%s' and '%s'.",
+			shell.FormatSh, shell.FormatFish,
+		),
+	)
+	flags.BoolVar(
+		&args.status,
+		"status",
+		false,
+		"Instead of logging in, report if the stored configuration can be used, without "+
+			"sending any request to the server. Prints the URL, the user, the account, "+
+			"the issuer of the tokens and when the session expires, and fails if it "+
+			"can't be used. Use the global '--output json' option to get the details "+
+			"in JSON format.",
+	)
+
+	// Complete the values that have well known alternatives:
+	completion.AddWords(flags, "url", urlAliasNames()...)
+	completion.AddWords(flags, "scope", sdk.DefaultScopes...)
+	completion.AddWords(flags, "add-scope", sdk.DefaultScopes...)
+	completion.AddWords(flags, "client-id", config.PreferredClientID, config.DeprecatedClientID)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	var err error
+	// Report the status of the stored session if requested:
+	if args.status {
+		return runStatus(cmd)
+	}
 
-	// Check mandatory options:
-	if args.url == "" {
-		return fmt.Errorf("Option '--url' is mandatory")
+	// Check the options:
+	err := checkOptions(cmd)
+	if err != nil {
+		return err
+	}
+	err = checkURL()
+	if err != nil {
+		return err
+	}
+
+	// Complete the credentials that haven't been given in the command line:
+	tokenSource, err := completeCredentials()
+	if err != nil {
+		return err
 	}
 
-	// Check that we have some kind of credentials:
+	// Check that we have exactly one kind of credentials:
+	err = checkCredentials()
+	if err != nil {
+		return err
+	}
 	havePassword := args.user != "" && args.password != ""
-	haveSecret := args.clientID != "" && args.clientSecret != ""
 	haveToken := args.token != ""
-	if !havePassword && !haveSecret && !haveToken {
-		return fmt.Errorf("In order to log in it is mandatory to use '--token', '--user' and " +
-			"'--password', or '--client-id' and '--client-secret'.")
+
+	// Make sure that the user knows that the password will be stored in clear text:
+	if havePassword && args.persistent && !args.useKeyring && !args.printConfig &&
+		!args.toEnv {
+		err = confirmPersistent()
+		if err != nil {
+			return err
+		}
 	}
 
 	// Inform the user that it isn't recommended to authenticate with user name and password:
@@ -166,155 +419,721 @@ func run(cmd *cobra.Command, argv []string) error {
 		fmt.Fprintf(
 			os.Stderr,
 			"Authenticating with a user name and password is deprecated. To avoid "+
-				"this warning go to 'https://cloud.redhat.com/openshift/token' "+
-				"to obtain your offline access token and then login using the "+
-				"'--token' option.\n",
+				"this warning go to '%s' to obtain your offline access token "+
+				"and then login using the '--token' option.\n",
+			urls.TokenPage(args.url),
 		)
 	}
 
 	// If a token has been provided parse it:
 	var token *jwt.Token
 	if haveToken {
-		parser := new(jwt.Parser)
-		token, _, err = parser.ParseUnverified(args.token, jwt.MapClaims{})
+		token, err = parseToken(tokenSource)
 		if err != nil {
-			return fmt.Errorf("Can't parse token '%s': %v", args.token, err)
+			return err
 		}
 	}
 
-	// Initially the default OpenID details will be the preferred ones:
-	defaultTokenURL := preferredTokenURL
-	defaultClientID := preferredClientID
-
-	// If authentication is performed with a user name and password then select the deprecated
-	// OpenID details. Otherwise select them according to the issuer of the token.
-	if havePassword {
-		defaultTokenURL = deprecatedTokenURL
-		defaultClientID = deprecatedClientID
-	} else if haveToken {
-		issuerURL, err := tokenIssuer(token)
-		if err != nil {
-			return fmt.Errorf("Can't get token issuer: %v", err)
-		}
-		if issuerURL != nil && strings.EqualFold(issuerURL.Hostname(), deprecatedIssuer) {
-			defaultTokenURL = deprecatedTokenURL
-			defaultClientID = deprecatedClientID
-		}
-	}
-
-	// Apply the default OpenID details if not explicitly provided by the user:
-	tokenURL := defaultTokenURL
-	if args.tokenURL != "" {
-		tokenURL = args.tokenURL
-	}
-	clientID := defaultClientID
-	if args.clientID != "" {
-		clientID = args.clientID
+	// Select the OpenID details:
+	tokenURL, clientID, deprecated, err := selectOpenID(havePassword, token)
+	if err != nil {
+		return err
 	}
 
 	// Load the configuration file:
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+		return output.Errorf(codeConfig, "Can't load config file: %v", err)
 	}
 	if cfg == nil {
 		cfg = new(config.Config)
 	}
 
+	// Make sure that the user wants to replace the credentials stored for a different server:
+	if !args.dryRun && !args.toEnv && !args.printConfig {
+		err = confirmReplace(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Keep the OpenID details and the scopes of the previous login when they aren't explicitly
+	// given in the command line:
+	tokenURL, clientID = keepOpenID(cfg, tokenURL, clientID, deprecated)
+	scopes := args.scopes
+	if keepStored(cfg, deprecated) && !cmd.Flags().Changed("scope") && len(cfg.Scopes) > 0 {
+		scopes = cfg.Scopes
+	}
+	if debug.Enabled() {
+		glog.Infof("Selected token URL '%s' and client identifier '%s'", tokenURL, clientID)
+	}
+
 	// Update the configuration with the values given in the command line:
 	cfg.TokenURL = tokenURL
 	cfg.ClientID = clientID
 	cfg.ClientSecret = args.clientSecret
-	cfg.Scopes = args.scopes
+	cfg.Scopes = config.MergeScopes(scopes, args.addScopes)
 	cfg.URL = args.url
 	cfg.User = args.user
 	cfg.Password = args.password
-	cfg.Insecure = args.insecure
+	cfg.SetInsecure(args.insecure)
+	cfg.Keyring = args.useKeyring
+	cfg.CAFile = ""
+	if args.caFile != "" {
+		cfg.CAFile, err = filepath.Abs(args.caFile)
+		if err != nil {
+			return output.Errorf(
+				codeInvalidOption,
+				"Can't get absolute path of CA file '%s': %v",
+				args.caFile, err,
+			)
+		}
+	}
 	cfg.AccessToken = ""
 	cfg.RefreshToken = ""
+	cfg.DeprecatedAuth = deprecated
+	cfg.DeprecatedAuthWarned = nil
+
+	// Ask users of tokens issued by the deprecated OpenID server to get new ones. Users of
+	// user names and passwords have already been warned.
+	if deprecated {
+		if haveToken {
+			fmt.Fprintf(os.Stderr, "%s\n", config.DeprecatedAuthWarning(args.url))
+		}
+		now := time.Now().UTC()
+		cfg.DeprecatedAuthWarned = &now
+	}
 
 	// Put the token in the place of the configuration that corresponds to its type:
 	if haveToken {
-		typ, err := tokenType(token)
+		err = cfg.SetToken(args.token, token)
 		if err != nil {
-			return fmt.Errorf("Can't extract type from 'typ' claim of token '%s': %v", args.token, err)
+			return output.Errorf(codeInvalidToken, "Can't use token: %v", err)
 		}
-		switch typ {
-		case "Bearer":
-			cfg.AccessToken = args.token
-		case "Refresh", "Offline":
-			cfg.RefreshToken = args.token
-		case "":
-			return fmt.Errorf("Don't know how to handle empty type in token '%s'", args.token)
-		default:
-			return fmt.Errorf("Don't know how to handle token type '%s' in token '%s'", typ, args.token)
+		if cfg.RefreshToken == args.token && args.maxTokenAge > 0 {
+			err = checkTokenAge(token)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	// Create a connection and get the token to verify that the crendentials are correct:
-	connection, err := cfg.Connection()
-	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+	// Get the tokens using the browser if requested:
+	if args.browser {
+		cfg.AccessToken, cfg.RefreshToken, err = browserLogin(
+			tokenURL, clientID, cfg.Scopes, args.insecure, cfg.CAFile,
+		)
+		if err != nil {
+			return output.Errorf(codeAuthenticationFailed, "Can't log in with the browser: %v", err)
+		}
 	}
-	accessToken, refreshToken, err := connection.Tokens()
+
+	// Check that the resulting configuration can be used to connect:
+	err = cfg.Validate()
 	if err != nil {
-		return fmt.Errorf("Can't get token: %v", err)
+		return output.Errorf(codeInvalidConfig, "Configuration isn't valid: %v", err)
+	}
+
+	// Create a connection and get the token to verify that the crendentials are correct, unless
+	// we have been explicitly asked to only store them:
+	if args.storedOnly {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: The credentials have not been verified because the "+
+				"'--stored-credentials-only' option was used, and no request has "+
+				"been sent to the server. If they aren't correct the next command "+
+				"that uses them will fail.\n",
+		)
+	} else {
+		connection, err := ocm.ConnectionFor(cfg)
+		if err != nil {
+			return output.Errorf(codeConnection, "%v", err)
+		}
+		defer config.Close(connection)
+		accessToken, refreshToken, err := config.RetryTokens(
+			connection, args.retries, args.retryDelay,
+		)
+		if err != nil {
+			return output.Errorf(codeAuthenticationFailed, "Can't get token: %v", err)
+		}
+		cfg.AccessToken = accessToken
+		cfg.RefreshToken = refreshToken
 	}
 
 	// Save the configuration, but clear the user name and password before unless we have
 	// explicitly been asked to store them persistently:
-	cfg.AccessToken = accessToken
-	cfg.RefreshToken = refreshToken
 	if !args.persistent {
 		cfg.User = ""
 		cfg.Password = ""
 	}
-	err = config.Save(cfg)
-	if err != nil {
-		return fmt.Errorf("Can't save config file: %v", err)
+	return saveConfig(cfg)
+}
+
+// checkCredentials checks that exactly one kind of credentials has been given, that it is complete,
+// and that it can be used with the rest of the options. The client identifier alone doesn't count
+// as a kind of credentials, as it can also be used to select the client that refreshes a token.
+func checkCredentials() error {
+	var kinds []string
+	if args.token != "" {
+		kinds = append(kinds, "a token")
+	}
+	if args.user != "" || args.password != "" {
+		kinds = append(kinds, "a user name and password")
+	}
+	if args.clientSecret != "" {
+		kinds = append(kinds, "a client identifier and secret")
+	}
+	if args.browser {
+		kinds = append(kinds, "the browser")
+	}
+	if len(kinds) > 1 {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Only one kind of credentials can be used, but got %s. Pick one of them "+
+				"and try again.",
+			strings.Join(kinds, ", "),
+		)
+	}
+	if args.user != "" && args.password == "" {
+		return output.Errorf(codeMissingCredentials, "Option '--user' requires '--password'")
+	}
+	if args.password != "" && args.user == "" {
+		return output.Errorf(codeMissingCredentials, "Option '--password' requires '--user'")
+	}
+	if args.clientSecret != "" && args.clientID == "" {
+		return output.Errorf(
+			codeMissingCredentials,
+			"Option '--client-secret' requires '--client-id'",
+		)
+	}
+	if len(kinds) == 0 {
+		return output.Errorf(
+			codeMissingCredentials,
+			"In order to log in it is mandatory to use '--token', '--user' and "+
+				"'--password', '--client-id' and '--client-secret', or '--browser'.",
+		)
 	}
 
+	// When the credentials are only stored the user name and password are the only thing that will
+	// be saved, so they need to be persistent:
+	if args.storedOnly && args.password != "" && !args.persistent {
+		return output.Errorf(
+			codeMissingOption,
+			"Option '--stored-credentials-only' requires '--persistent' when "+
+				"authenticating with a user name and password",
+		)
+	}
 	return nil
 }
 
-// tokenIssuer extracts the value of the `iss` claim. It then returns tha value as a URL, or nil if
-// there is no such claim.
-func tokenIssuer(token *jwt.Token) (issuer *url.URL, err error) {
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		err = fmt.Errorf("expected map claims but got %T", claims)
+// flagAliases contains the alternative names of the options of the command, and the names of the
+// options that they correspond to.
+var flagAliases = map[string]string{
+	"offline": "stored-credentials-only",
+}
+
+// normalizeFlag replaces the alternative names of options with the names of the options that
+// they correspond to.
+func normalizeFlag(fs *pflag.FlagSet, name string) pflag.NormalizedName {
+	alias, ok := flagAliases[name]
+	if ok {
+		name = alias
+	}
+	return pflag.NormalizedName(name)
+}
+
+// selectOpenID returns the OpenID token URL and client identifier. If they aren't explicitly
+// provided by the user then the defaults are the preferred ones, except if authentication is
+// performed with a user name and password, then the deprecated ones are used. When a token is
+// given they are selected according to its issuer. The returned flag indicates if the defaults are
+// the deprecated ones.
+func selectOpenID(havePassword bool, token *jwt.Token) (tokenURL string, clientID string,
+	deprecated bool, err error) {
+	tokenURL = config.PreferredTokenURL
+	clientID = config.PreferredClientID
+	if havePassword {
+		tokenURL = config.DeprecatedTokenURL
+		clientID = config.DeprecatedClientID
+	} else if token != nil {
+		tokenURL, clientID, err = config.TokenDefaults(token)
+		if err != nil {
+			err = output.Errorf(codeInvalidToken, "Can't select OpenID details: %v", err)
+			return
+		}
+	}
+	deprecated = tokenURL == config.DeprecatedTokenURL
+	if args.tokenURL != "" {
+		tokenURL = args.tokenURL
+	}
+	if args.clientID != "" {
+		clientID = args.clientID
+	}
+	return
+}
+
+// keepOpenID returns the OpenID token URL and client identifier stored in the given configuration
+// instead of the selected defaults, unless they have been explicitly given in the command line.
+// The stored values are only kept if the keepStored function allows it.
+func keepOpenID(cfg *config.Config, tokenURL string, clientID string,
+	deprecated bool) (string, string) {
+	if !keepStored(cfg, deprecated) {
+		return tokenURL, clientID
+	}
+	if args.tokenURL == "" && cfg.TokenURL != "" {
+		tokenURL = cfg.TokenURL
+	}
+	if args.clientID == "" && cfg.ClientID != "" {
+		clientID = cfg.ClientID
+	}
+	return tokenURL, clientID
+}
+
+// keepStored checks if the OpenID details and the scopes stored in the given configuration can be
+// used for the new login. That is only the case if they were used with the same API gateway, the
+// same OpenID server, deprecated or not, that the new credentials require, and the same kind of
+// credentials.
+func keepStored(cfg *config.Config, deprecated bool) bool {
+	if cfg.URL != "" && !sameURL(cfg.URL, args.url) {
+		return false
+	}
+	stored := cfg.DeprecatedAuth || cfg.TokenURL == config.DeprecatedTokenURL
+	if stored != deprecated {
+		return false
+	}
+	return storedCredentials(cfg) == givenCredentials()
+}
+
+// Kinds of credentials, used to check if the OpenID details stored in the configuration can be
+// used with the credentials given in the command line. The browser is considered a token, as that
+// is what it stores.
+const (
+	credentialsToken    = "token"
+	credentialsPassword = "password"
+	credentialsClient   = "client"
+)
+
+// storedCredentials returns the kind of credentials stored in the given configuration.
+func storedCredentials(cfg *config.Config) string {
+	switch {
+	case cfg.ClientSecret != "":
+		return credentialsClient
+	case cfg.User != "" || cfg.Password != "":
+		return credentialsPassword
+	default:
+		return credentialsToken
+	}
+}
+
+// givenCredentials returns the kind of credentials given in the command line.
+func givenCredentials() string {
+	switch {
+	case args.clientSecret != "":
+		return credentialsClient
+	case args.user != "" || args.password != "":
+		return credentialsPassword
+	default:
+		return credentialsToken
+	}
+}
+
+// saveConfig saves the configuration, or prints it or exports it to the environment if one of
+// the '--print-config' or '--to-env' options was used. With the '--dry-run' option it only reports
+// that the credentials are valid.
+func saveConfig(cfg *config.Config) error {
+	if args.dryRun {
+		fmt.Printf("credentials valid\n")
+		return nil
+	}
+	if args.toEnv {
+		return printEnv(cfg)
+	}
+	if args.printConfig {
+		data, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+		if err != nil {
+			return output.Errorf(codeConfig, "Can't marshal config: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	err := config.Save(cfg)
+	if err != nil {
+		return output.Errorf(codeConfig, "Can't save config file: %v", err)
+	}
+	return nil
+}
+
+// completeCredentials reads the password and the token from files or from the standard input if
+// requested, and takes the credentials that haven't been given in the command line from the
+// environment. Then it asks for the password or the client secret if they are still missing and we
+// are running interactively. This avoids having them in the shell history. The returned text
+// describes where the token was taken from, and is empty if it was given in the command line.
+func completeCredentials() (tokenSource string, err error) {
+	tokenSource, err = readSecrets()
+	if err != nil {
+		return
+	}
+	if args.clientID == "" {
+		args.clientID = os.Getenv(clientIDEnv)
+	}
+	if args.clientSecret == "" {
+		args.clientSecret = os.Getenv(clientSecretEnv)
+	}
+	if args.token == "" {
+		args.token = os.Getenv(tokenEnv)
+		if args.token != "" {
+			tokenSource = fmt.Sprintf("environment variable '%s'", tokenEnv)
+		}
+	}
+	if args.token != "" || args.browser {
 		return
 	}
-	claim, ok := claims["iss"]
-	if !ok {
+	if args.user != "" && args.password == "" {
+		args.password, err = askSecret("password", "Password:")
+	} else if args.clientID != "" && args.clientSecret == "" {
+		args.clientSecret, err = askSecret("client-secret", "Client secret:")
+	}
+	return
+}
+
+// checkOptions checks that the command line options are valid and consistent with each other.
+func checkOptions(cmd *cobra.Command) error {
+	if args.url == "" {
+		return output.Errorf(codeMissingURL, "Option '--url' is mandatory")
+	}
+	if args.insecure && args.caFile != "" {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Options '--insecure' and '--ca-file' are contradictory, use only one of them",
+		)
+	}
+	if args.toEnv && (args.storedOnly || args.printConfig) {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Option '--to-env' can't be used with '--stored-credentials-only' or "+
+				"'--print-config'",
+		)
+	}
+	if args.dryRun && (args.persistent || args.storedOnly || args.printConfig || args.toEnv) {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Option '--dry-run' can't be used with '--persistent', "+
+				"'--stored-credentials-only', '--print-config' or '--to-env'",
+		)
+	}
+	if cmd.Flags().Changed("format") && !args.toEnv {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Option '--format' can only be used with '--to-env'",
+		)
+	}
+	if args.format != shell.FormatSh && args.format != shell.FormatFish {
+		return output.Errorf(
+			codeInvalidOption,
+			"Unknown shell format '%s', valid values are '%s' and '%s'",
+			args.format, shell.FormatSh, shell.FormatFish,
+		)
+	}
+	if args.password != "" && args.passwordFile != "" {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Options '--password' and '--password-file' can't be used together",
+		)
+	}
+	if args.token != "" && args.tokenFile != "" {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Options '--token' and '--token-file' can't be used together",
+		)
+	}
+	if (args.password == stdinName || args.passwordFile == stdinName) &&
+		(args.token == stdinName || args.tokenFile == stdinName) {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Only one of the password and the token can be read from the standard input",
+		)
+	}
+	if args.retries < 0 {
+		return output.Errorf(
+			codeInvalidOption,
+			"Value of option '--retries' can't be negative, but it is %d",
+			args.retries,
+		)
+	}
+	return nil
+}
+
+// checkURL replaces the URL given in the command line with the URL of the API gateway if it is an
+// alias, checks that it is valid, and checks that the server can be reached if requested.
+func checkURL() (err error) {
+	args.url, err = resolveURL(args.url)
+	if err != nil || !args.checkConnectivity {
 		return
 	}
-	value, ok := claim.(string)
-	if !ok {
-		err = fmt.Errorf("expected string 'iss' but got %T", claim)
+	return checkConnectivity(args.url, args.insecure, args.caFile)
+}
+
+// resolveURL returns the URL of the API gateway corresponding to the given alias, or the given
+// text if it is already an absolute URL.
+func resolveURL(text string) (result string, err error) {
+	result, ok := urlAliases[strings.ToLower(text)]
+	if ok {
 		return
 	}
-	issuer, err = url.Parse(value)
+	parsed, err := url.Parse(text)
+	switch {
+	case err != nil:
+		err = output.Errorf(
+			codeInvalidOption,
+			"Can't parse value '%s' of option '--url': %v",
+			text, err,
+		)
+	case parsed.Scheme == "" || parsed.Opaque != "":
+		err = output.Errorf(
+			codeInvalidOption,
+			"Value '%s' of option '--url' isn't an absolute URL or a known alias, valid "+
+				"aliases are %s",
+			text, aliasesText(),
+		)
+	case parsed.Scheme != "http" && parsed.Scheme != "https":
+		err = output.Errorf(
+			codeInvalidOption,
+			"Value '%s' of option '--url' has unsupported scheme '%s', it should be "+
+				"'http' or 'https'",
+			text, parsed.Scheme,
+		)
+	case parsed.Hostname() == "":
+		err = output.Errorf(
+			codeInvalidOption,
+			"Value '%s' of option '--url' doesn't contain a host name",
+			text,
+		)
+	default:
+		result = text
+	}
 	return
 }
 
-// tokenType extracts the value of the `typ` claim. It returns the value as a string, or the empty
-// string if there is no such claim.
-func tokenType(token *jwt.Token) (typ string, err error) {
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		err = fmt.Errorf("expected map claims but got %T", claims)
+// urlAliasNames returns the sorted list of URL aliases.
+func urlAliasNames() []string {
+	names := make([]string, 0, len(urlAliases))
+	for name := range urlAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// aliasesText returns the sorted list of URL aliases, quoted and separated by commas.
+func aliasesText() string {
+	names := urlAliasNames()
+	for i, name := range names {
+		names[i] = fmt.Sprintf("'%s'", name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseToken parses the token given by the user and checks that it hasn't expired. When the token
+// wasn't given in the command line it isn't included in the error messages, only the source.
+func parseToken(source string) (token *jwt.Token, err error) {
+	token, err = config.ParseToken(args.token)
+	if err != nil {
+		if source != "" {
+			err = output.Errorf(
+				codeInvalidToken,
+				"Can't parse token from %s: %v",
+				source, err,
+			)
+		} else {
+			err = output.Errorf(
+				codeInvalidToken,
+				"Can't parse token '%s': %v",
+				args.token, err,
+			)
+		}
 		return
 	}
-	claim, ok := claims["typ"]
-	if !ok {
+	expiry, err := config.TokenExpiry(token)
+	if err != nil {
+		err = output.Errorf(
+			codeInvalidToken,
+			"Can't extract expiry time from 'exp' claim: %v",
+			err,
+		)
 		return
 	}
-	value, ok := claim.(string)
-	if !ok {
-		err = fmt.Errorf("expected string 'typ' but got %T", claim)
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		err = output.Errorf(
+			codeExpiredToken,
+			"The provided token expired at %s, get a new one and try again",
+			expiry.Local().Format(time.RFC1123),
+		)
+	}
+	return
+}
+
+// checkTokenAge writes a warning if the given refresh token was issued longer ago than the value
+// of the '--max-token-age' option.
+func checkTokenAge(token *jwt.Token) error {
+	issued, err := config.TokenIssueTime(token)
+	if err != nil {
+		return output.Errorf(
+			codeInvalidToken,
+			"Can't extract issue time from 'iat' claim: %v",
+			err,
+		)
+	}
+	age := time.Since(issued)
+	if !issued.IsZero() && age > args.maxTokenAge {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: The token was issued %d days ago. Old tokens may stop "+
+				"being accepted before they expire. To avoid problems go to "+
+				"'%s' to obtain a new one.\n",
+			int(age.Hours()/24), urls.TokenPage(args.url),
+		)
+	}
+	return nil
+}
+
+// printEnv prints the shell statements that export the URL and the token of the given
+// configuration. The refresh token is preferred because it lasts longer.
+func printEnv(cfg *config.Config) error {
+	token := cfg.RefreshToken
+	if token == "" {
+		token = cfg.AccessToken
+	}
+	err := shell.Export(os.Stdout, args.format, "OCM_URL", cfg.URL)
+	if err != nil {
+		return output.Errorf(codeOutput, "Can't print environment: %v", err)
+	}
+	err = shell.Export(os.Stdout, args.format, tokenEnv, token)
+	if err != nil {
+		return output.Errorf(codeOutput, "Can't print environment: %v", err)
+	}
+	return nil
+}
+
+// confirmPersistent warns the user that the '--persistent' option stores the password in clear
+// text, and asks for confirmation unless it has already been given with the '--yes' option or
+// with the environment variable.
+func confirmPersistent() error {
+	file, err := config.Location()
+	if err != nil {
+		return output.Errorf(codeConfig, "Can't find config file: %v", err)
+	}
+	fmt.Fprintf(
+		os.Stderr,
+		"WARNING: The '--persistent' option stores the user name and password in clear "+
+			"text in '%s'. Consider using the '--use-keyring' option, or logging in "+
+			"with the offline access token that can be obtained from '%s'.\n",
+		file, urls.TokenPage(args.url),
+	)
+	if args.yes {
+		return nil
+	}
+	value := os.Getenv(confirmPersistentEnv)
+	if value != "" {
+		confirmed, err := strconv.ParseBool(value)
+		if err != nil {
+			return output.Errorf(
+				codeInvalidOption,
+				"Value '%s' of environment variable '%s' isn't a boolean",
+				value, confirmPersistentEnv,
+			)
+		}
+		if confirmed {
+			return nil
+		}
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return output.Errorf(
+			codeNotConfirmed,
+			"Option '--persistent' requires '--yes', or setting the '%s' environment "+
+				"variable to 'true', when the standard input isn't a terminal",
+			confirmPersistentEnv,
+		)
+	}
+	confirmed := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Store password in clear text at '%s'?", file),
+	}
+	err = survey.AskOne(prompt, &confirmed, nil)
+	if err != nil {
+		return output.Errorf(codeNotConfirmed, "Can't ask for confirmation: %v", err)
+	}
+	if !confirmed {
+		return output.Errorf(
+			codeNotConfirmed,
+			"The password hasn't been stored, log in again without the '--persistent' "+
+				"option",
+		)
+	}
+	return nil
+}
+
+// confirmReplace checks if the given configuration contains credentials or tokens for a server
+// different than the one given with the '--url' option, and in that case asks for confirmation
+// before replacing them, unless the '--force' option has been used.
+func confirmReplace(cfg *config.Config) error {
+	if args.force || cfg.URL == "" || sameURL(cfg.URL, args.url) {
+		return nil
+	}
+	if cfg.AccessToken == "" && cfg.RefreshToken == "" && cfg.Password == "" &&
+		cfg.ClientSecret == "" {
+		return nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return output.Errorf(
+			codeNotConfirmed,
+			"The configuration contains credentials for '%s'. Use the '--force' option "+
+				"to replace them with credentials for '%s', or the '--profile' option "+
+				"to keep both.",
+			cfg.URL, args.url,
+		)
+	}
+	confirmed := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf(
+			"Replace the credentials for '%s' with credentials for '%s'?",
+			cfg.URL, args.url,
+		),
+	}
+	err := survey.AskOne(prompt, &confirmed, nil)
+	if err != nil {
+		return output.Errorf(codeNotConfirmed, "Can't ask for confirmation: %v", err)
+	}
+	if !confirmed {
+		return output.Errorf(
+			codeNotConfirmed,
+			"The credentials for '%s' haven't been replaced",
+			cfg.URL,
+		)
+	}
+	return nil
+}
+
+// sameURL checks if the given URLs are the same, ignoring the case and trailing slashes.
+func sameURL(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// askSecret asks the user for the value of a secret option, without echoing it. It returns an
+// error if the standard input isn't a terminal, as in that case the option is mandatory.
+func askSecret(option string, message string) (value string, err error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		err = output.Errorf(
+			codeMissingCredentials,
+			"Option '--%s' is mandatory when the standard input isn't a terminal",
+			option,
+		)
 		return
 	}
-	typ = value
+	prompt := &survey.Password{
+		Message: message,
+	}
+	err = survey.AskOne(prompt, &value, nil)
+	if err != nil {
+		err = output.Errorf(codeMissingCredentials, "Can't read %s: %v", option, err)
+	}
 	return
 }
@@ -0,0 +1,580 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package login
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+)
+
+func TestLogin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Login")
+}
+
+var _ = Describe("Check credentials", func() {
+	saved := args
+
+	AfterEach(func() {
+		args = saved
+	})
+
+	DescribeTable(
+		"Valid",
+		func(setup func()) {
+			setup()
+			Expect(checkCredentials()).To(Succeed())
+		},
+		Entry(
+			"Token",
+			func() {
+				args.token = "my-token"
+			},
+		),
+		Entry(
+			"Token and client identifier",
+			func() {
+				args.token = "my-token"
+				args.clientID = "my-client"
+			},
+		),
+		Entry(
+			"User and password",
+			func() {
+				args.user = "my-user"
+				args.password = "my-password"
+			},
+		),
+		Entry(
+			"Client identifier and secret",
+			func() {
+				args.clientID = "my-client"
+				args.clientSecret = "my-secret"
+			},
+		),
+		Entry(
+			"Browser",
+			func() {
+				args.browser = true
+			},
+		),
+	)
+
+	DescribeTable(
+		"Invalid",
+		func(setup func(), expectedCode string, expectedReason string) {
+			setup()
+			err := checkCredentials()
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&output.Error{}))
+			Expect(err.(*output.Error).Code).To(Equal(expectedCode))
+			Expect(err.Error()).To(ContainSubstring(expectedReason))
+		},
+		Entry(
+			"Nothing",
+			func() {},
+			codeMissingCredentials,
+			"it is mandatory",
+		),
+		Entry(
+			"Token and user",
+			func() {
+				args.token = "my-token"
+				args.user = "my-user"
+				args.password = "my-password"
+			},
+			codeConflictingOptions,
+			"got a token, a user name and password",
+		),
+		Entry(
+			"Token and client secret",
+			func() {
+				args.token = "my-token"
+				args.clientID = "my-client"
+				args.clientSecret = "my-secret"
+			},
+			codeConflictingOptions,
+			"got a token, a client identifier and secret",
+		),
+		Entry(
+			"User and client secret",
+			func() {
+				args.user = "my-user"
+				args.password = "my-password"
+				args.clientID = "my-client"
+				args.clientSecret = "my-secret"
+			},
+			codeConflictingOptions,
+			"got a user name and password, a client identifier and secret",
+		),
+		Entry(
+			"All",
+			func() {
+				args.token = "my-token"
+				args.user = "my-user"
+				args.password = "my-password"
+				args.clientID = "my-client"
+				args.clientSecret = "my-secret"
+			},
+			codeConflictingOptions,
+			"got a token, a user name and password, a client identifier and secret",
+		),
+		Entry(
+			"Browser and token",
+			func() {
+				args.browser = true
+				args.token = "my-token"
+			},
+			codeConflictingOptions,
+			"got a token, the browser",
+		),
+		Entry(
+			"User without password",
+			func() {
+				args.user = "my-user"
+			},
+			codeMissingCredentials,
+			"Option '--user' requires '--password'",
+		),
+		Entry(
+			"Password without user",
+			func() {
+				args.password = "my-password"
+			},
+			codeMissingCredentials,
+			"Option '--password' requires '--user'",
+		),
+		Entry(
+			"Secret without client identifier",
+			func() {
+				args.clientSecret = "my-secret"
+			},
+			codeMissingCredentials,
+			"Option '--client-secret' requires '--client-id'",
+		),
+		Entry(
+			"Token and user without password",
+			func() {
+				args.token = "my-token"
+				args.user = "my-user"
+			},
+			codeConflictingOptions,
+			"got a token, a user name and password",
+		),
+	)
+})
+
+var _ = Describe("Flag aliases", func() {
+	saved := args
+
+	AfterEach(func() {
+		args = saved
+	})
+
+	It("Accepts '--offline' as '--stored-credentials-only'", func() {
+		err := Cmd.Flags().Parse([]string{"--offline"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.storedOnly).To(BeTrue())
+	})
+})
+
+var _ = Describe("Confirm persistent", func() {
+	saved := args
+
+	AfterEach(func() {
+		args = saved
+		os.Unsetenv(confirmPersistentEnv)
+	})
+
+	It("Accepts the '--yes' option", func() {
+		args.yes = true
+		Expect(confirmPersistent()).To(Succeed())
+	})
+
+	It("Accepts the environment variable", func() {
+		os.Setenv(confirmPersistentEnv, "true")
+		Expect(confirmPersistent()).To(Succeed())
+	})
+
+	It("Rejects invalid value of the environment variable", func() {
+		os.Setenv(confirmPersistentEnv, "maybe")
+		err := confirmPersistent()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*output.Error).Code).To(Equal(codeInvalidOption))
+	})
+})
+
+var _ = Describe("Confirm replace", func() {
+	saved := args
+
+	AfterEach(func() {
+		args = saved
+	})
+
+	DescribeTable(
+		"Doesn't ask",
+		func(setup func(), cfg *config.Config) {
+			args.url = "https://your.api"
+			setup()
+			Expect(confirmReplace(cfg)).To(Succeed())
+		},
+		Entry(
+			"Empty configuration",
+			func() {},
+			&config.Config{},
+		),
+		Entry(
+			"Same URL",
+			func() {},
+			&config.Config{URL: "https://your.api/", RefreshToken: "my-token"},
+		),
+		Entry(
+			"Different URL without credentials",
+			func() {},
+			&config.Config{URL: "https://my.api"},
+		),
+		Entry(
+			"Different URL with '--force'",
+			func() {
+				args.force = true
+			},
+			&config.Config{URL: "https://my.api", RefreshToken: "my-token"},
+		),
+	)
+})
+
+var _ = Describe("Session status", func() {
+	makeToken := func(claims jwt.MapClaims) string {
+		text, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).
+			SignedString([]byte("secret"))
+		Expect(err).ToNot(HaveOccurred())
+		return text
+	}
+
+	It("Extracts the details from the refresh token", func() {
+		exp := time.Now().Add(time.Hour).Unix()
+		cfg := &config.Config{
+			URL: "https://my.api",
+			RefreshToken: makeToken(jwt.MapClaims{
+				"typ":                "Refresh",
+				"exp":                exp,
+				"sub":                "my-account",
+				"preferred_username": "my-user",
+				"iss":                "https://my.sso",
+			}),
+		}
+		status := makeStatus(cfg)
+		Expect(status.URL).To(Equal("https://my.api"))
+		Expect(status.User).To(Equal("my-user"))
+		Expect(status.AccountID).To(Equal("my-account"))
+		Expect(status.Issuer).To(Equal("https://my.sso"))
+		Expect(status.Expires).ToNot(BeNil())
+		Expect(status.Expires.Unix()).To(Equal(exp))
+	})
+
+	It("Doesn't expire with credentials", func() {
+		cfg := &config.Config{
+			User:     "my-user",
+			Password: "my-password",
+			AccessToken: makeToken(jwt.MapClaims{
+				"typ": "Bearer",
+				"exp": time.Now().Add(time.Minute).Unix(),
+			}),
+		}
+		status := makeStatus(cfg)
+		Expect(status.User).To(Equal("my-user"))
+		Expect(status.Expires).To(BeNil())
+	})
+})
+
+var _ = Describe("Keep OpenID details", func() {
+	saved := args
+
+	AfterEach(func() {
+		args = saved
+	})
+
+	It("Keeps stored values for the same server", func() {
+		cfg := &config.Config{
+			TokenURL: "https://my.sso/token",
+			ClientID: "my-client",
+		}
+		tokenURL, clientID := keepOpenID(
+			cfg, config.PreferredTokenURL, config.PreferredClientID, false,
+		)
+		Expect(tokenURL).To(Equal("https://my.sso/token"))
+		Expect(clientID).To(Equal("my-client"))
+	})
+
+	It("Prefers values given in the command line", func() {
+		args.tokenURL = "https://your.sso/token"
+		args.clientID = "your-client"
+		cfg := &config.Config{
+			TokenURL: "https://my.sso/token",
+			ClientID: "my-client",
+		}
+		tokenURL, clientID := keepOpenID(cfg, args.tokenURL, args.clientID, false)
+		Expect(tokenURL).To(Equal("https://your.sso/token"))
+		Expect(clientID).To(Equal("your-client"))
+	})
+
+	DescribeTable(
+		"Doesn't keep values for a different kind of credentials",
+		func(cfg *config.Config, setup func()) {
+			cfg.TokenURL = "https://my.sso/token"
+			cfg.ClientID = "my-client"
+			setup()
+			tokenURL, clientID := keepOpenID(
+				cfg, config.PreferredTokenURL, config.PreferredClientID, false,
+			)
+			Expect(tokenURL).To(Equal(config.PreferredTokenURL))
+			Expect(clientID).To(Equal(config.PreferredClientID))
+		},
+		Entry(
+			"Client credentials to token",
+			&config.Config{
+				ClientSecret: "my-secret",
+			},
+			func() {
+				args.token = "my-token"
+			},
+		),
+		Entry(
+			"User and password to token",
+			&config.Config{
+				User:     "my-user",
+				Password: "my-password",
+			},
+			func() {
+				args.token = "my-token"
+			},
+		),
+		Entry(
+			"Token to user and password",
+			&config.Config{
+				RefreshToken: "my-token",
+			},
+			func() {
+				args.user = "my-user"
+				args.password = "my-password"
+			},
+		),
+		Entry(
+			"User and password to client credentials",
+			&config.Config{
+				User:     "my-user",
+				Password: "my-password",
+			},
+			func() {
+				args.clientSecret = "my-secret"
+			},
+		),
+	)
+
+	DescribeTable(
+		"Keep stored values",
+		func(cfg *config.Config, setup func(), deprecated bool, expected bool) {
+			args.url = "https://my.api"
+			setup()
+			Expect(keepStored(cfg, deprecated)).To(Equal(expected))
+		},
+		Entry(
+			"Same server",
+			&config.Config{
+				URL:    "https://my.api/",
+				Scopes: []string{"my-scope"},
+			},
+			func() {},
+			false,
+			true,
+		),
+		Entry(
+			"Nothing stored",
+			&config.Config{},
+			func() {},
+			false,
+			true,
+		),
+		Entry(
+			"Different server",
+			&config.Config{
+				URL:    "https://your.api",
+				Scopes: []string{"my-scope"},
+			},
+			func() {},
+			false,
+			false,
+		),
+		Entry(
+			"Different server with the same credentials",
+			&config.Config{
+				URL:          "https://your.api",
+				RefreshToken: "my-token",
+				Scopes:       []string{"my-scope"},
+			},
+			func() {
+				args.token = "your-token"
+			},
+			false,
+			false,
+		),
+		Entry(
+			"Deprecated server",
+			&config.Config{
+				URL:      "https://my.api",
+				TokenURL: config.DeprecatedTokenURL,
+				Scopes:   []string{"my-scope"},
+			},
+			func() {},
+			false,
+			false,
+		),
+		Entry(
+			"Different kind of credentials",
+			&config.Config{
+				URL:          "https://my.api",
+				ClientSecret: "my-secret",
+				Scopes:       []string{"my-scope"},
+			},
+			func() {
+				args.token = "my-token"
+			},
+			false,
+			false,
+		),
+	)
+
+	It("Doesn't keep values for the deprecated server", func() {
+		cfg := &config.Config{
+			TokenURL: config.DeprecatedTokenURL,
+			ClientID: config.DeprecatedClientID,
+		}
+		tokenURL, clientID := keepOpenID(
+			cfg, config.PreferredTokenURL, config.PreferredClientID, false,
+		)
+		Expect(tokenURL).To(Equal(config.PreferredTokenURL))
+		Expect(clientID).To(Equal(config.PreferredClientID))
+	})
+})
+
+var _ = Describe("Check secret file", func() {
+	var file string
+
+	BeforeEach(func() {
+		tmp, err := ioutil.TempFile("", "secret-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = tmp.Name()
+		err = tmp.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.Remove(file)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Accepts file only accessible by the owner", func() {
+		err := os.Chmod(file, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checkSecretFile(file)).To(Succeed())
+	})
+
+	DescribeTable(
+		"Rejects file accessible by other users",
+		func(mode os.FileMode) {
+			err := os.Chmod(file, mode)
+			Expect(err).ToNot(HaveOccurred())
+			err = checkSecretFile(file)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*output.Error).Code).To(Equal(codeInvalidOption))
+		},
+		Entry("Readable by group", os.FileMode(0640)),
+		Entry("Writable by group", os.FileMode(0620)),
+		Entry("Readable by others", os.FileMode(0604)),
+	)
+})
+
+var _ = Describe("Resolve URL", func() {
+	DescribeTable(
+		"Valid",
+		func(text string, expected string) {
+			result, err := resolveURL(text)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("Production", "production", "https://api.openshift.com"),
+		Entry("Staging", "staging", "https://api.stage.openshift.com"),
+		Entry("Upper case alias", "Staging", "https://api.stage.openshift.com"),
+		Entry("Explicit URL", "https://my.api", "https://my.api"),
+		Entry("Explicit URL with port", "http://localhost:8000", "http://localhost:8000"),
+	)
+
+	DescribeTable(
+		"Invalid",
+		func(text string, expected string) {
+			_, err := resolveURL(text)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&output.Error{}))
+			Expect(err.(*output.Error).Code).To(Equal(codeInvalidOption))
+			Expect(err.Error()).To(ContainSubstring(expected))
+		},
+		Entry("Unknown alias", "prod", "'integration', 'production', 'staging'"),
+		Entry("Without scheme", "api.openshift.com", "isn't an absolute URL"),
+		Entry("Host and port without scheme", "localhost:8000", "isn't an absolute URL"),
+		Entry("Misspelled scheme", "htps://api.openshift.com", "unsupported scheme 'htps'"),
+		Entry("Unsupported scheme", "ftp://api.openshift.com", "unsupported scheme 'ftp'"),
+		Entry("Without host", "https://", "doesn't contain a host name"),
+		Entry("Only port", "https://:8000", "doesn't contain a host name"),
+		Entry("Bad escape", "https://api.openshift.com/%zz", "Can't parse value"),
+	)
+})
+
+var _ = Describe("Check connectivity", func() {
+	It("Reports refused connections", func() {
+		// Get a local port where nothing is listening:
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		address := listener.Addr().String()
+		err = listener.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = checkConnectivity("http://"+address, false, "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*output.Error).Code).To(Equal(codeUnreachable))
+		Expect(err.Error()).To(ContainSubstring("the connection was refused"))
+	})
+
+	It("Accepts reachable servers", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer listener.Close()
+
+		err = checkConnectivity("http://"+listener.Addr().String(), false, "")
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the '--check-connectivity' option of the 'login'
+// command, which checks that the API gateway can be reached before sending any credentials.
+
+package login
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+)
+
+// connectivityTimeout is the maximum time to wait for the connection and the TLS handshake.
+const connectivityTimeout = 10 * time.Second
+
+// checkConnectivity opens a TCP connection to the host of the given URL, and does the TLS
+// handshake if the scheme is 'https'. If that fails it returns an error explaining if the problem
+// is the name of the host, the connection or the TLS handshake, so that it isn't confused with
+// rejected credentials.
+func checkConnectivity(text string, insecure bool, caFile string) error {
+	parsed, err := url.Parse(text)
+	if err != nil {
+		return output.Errorf(codeInvalidOption, "Can't parse URL '%s': %v", text, err)
+	}
+	host := parsed.Hostname()
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+	address := net.JoinHostPort(host, port)
+
+	// Open the TCP connection:
+	dialer := &net.Dialer{
+		Timeout: connectivityTimeout,
+	}
+	connection, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return dialError(host, address, err)
+	}
+	defer connection.Close()
+	if parsed.Scheme != "https" {
+		return nil
+	}
+
+	// Do the TLS handshake, with the same certificate authorities that the connection will use:
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: insecure,
+	}
+	if caFile != "" && !insecure {
+		tlsConfig.RootCAs, err = config.LoadCAs(caFile)
+		if err != nil {
+			return output.Errorf(codeInvalidOption, "Can't load CA file: %v", err)
+		}
+	}
+	err = connection.SetDeadline(time.Now().Add(connectivityTimeout))
+	if err != nil {
+		return output.Errorf(codeUnreachable, "Can't set deadline for '%s': %v", address, err)
+	}
+	err = tls.Client(connection, tlsConfig).Handshake()
+	if err != nil {
+		return output.Errorf(
+			codeUnreachable,
+			"Connection to '%s' was established, but the TLS handshake failed: %v. Check "+
+				"the '--ca-file' and '--insecure' options.",
+			address, err,
+		)
+	}
+	return nil
+}
+
+// dialError converts the error returned when opening the TCP connection into an error that tells
+// apart problems with the name of the host from problems with the connection.
+func dialError(host, address string, err error) error {
+	var problem string
+	switch {
+	case isDNSError(err):
+		problem = "the host name '" + host + "' can't be resolved"
+	case isRefused(err):
+		problem = "the connection was refused"
+	case isTimeout(err):
+		problem = "the connection timed out"
+	default:
+		problem = "the connection failed"
+	}
+	return output.Errorf(
+		codeUnreachable,
+		"Can't connect to '%s', %s: %v. Check the '--url' option and the network.",
+		address, problem, err,
+	)
+}
+
+// isDNSError checks if the given dial error was caused by a failure to resolve the host name.
+func isDNSError(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if ok {
+		err = opErr.Err
+	}
+	_, ok = err.(*net.DNSError)
+	return ok
+}
+
+// isRefused checks if the given dial error was caused by the server refusing the connection.
+func isRefused(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	sysErr, ok := opErr.Err.(*os.SyscallError)
+	if ok && sysErr.Err == syscall.ECONNREFUSED {
+		return true
+	}
+	// On some platforms the system error isn't ECONNREFUSED, so check the message as well:
+	return strings.Contains(opErr.Err.Error(), "refused")
+}
+
+// isTimeout checks if the given dial error was caused by a timeout.
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
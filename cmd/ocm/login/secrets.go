@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to read passwords and tokens from files or from the
+// standard input, so that they don't need to be passed in the command line.
+
+package login
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/openshift-online/ocm-cli/pkg/output"
+)
+
+// stdinName is the value of the options that indicates that the secret should be read from the
+// standard input.
+const stdinName = "-"
+
+// readSecrets replaces the password and the token with the content of the files given with the
+// '--password-file' and '--token-file' options, or with the content of the standard input when the
+// value of any of those options, or of the '--password' and '--token' options, is '-'. The
+// returned text describes where the token was read from, and is empty if it wasn't read.
+func readSecrets() (tokenSource string, err error) {
+	passwordFile := args.passwordFile
+	if args.password == stdinName {
+		passwordFile = stdinName
+	}
+	if passwordFile != "" {
+		args.password, err = readSecret(passwordFile)
+		if err != nil {
+			return
+		}
+	}
+	tokenFile := args.tokenFile
+	if args.token == stdinName {
+		tokenFile = stdinName
+	}
+	if tokenFile != "" {
+		args.token, err = readSecret(tokenFile)
+		if err != nil {
+			return
+		}
+		tokenSource = "the standard input"
+		if tokenFile != stdinName {
+			tokenSource = fmt.Sprintf("file '%s'", tokenFile)
+		}
+	}
+	return
+}
+
+// readSecret reads a secret from the given file, or from the standard input if the name of the
+// file is '-'. Only the trailing line breaks are removed, any other white space is preserved.
+// Files that can be read by other users are rejected.
+func readSecret(file string) (value string, err error) {
+	var data []byte
+	if file == stdinName {
+		data, err = ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			err = output.Errorf(
+				codeMissingCredentials,
+				"Can't read secret from the standard input: %v",
+				err,
+			)
+			return
+		}
+	} else {
+		err = checkSecretFile(file)
+		if err != nil {
+			return
+		}
+		data, err = ioutil.ReadFile(file) // #nosec G304
+		if err != nil {
+			err = output.Errorf(
+				codeMissingCredentials,
+				"Can't read secret file '%s': %v",
+				file, err,
+			)
+			return
+		}
+	}
+	value = strings.TrimRight(string(data), "\r\n")
+	return
+}
+
+// checkSecretFile checks that the given file can't be read by other users. Permissions aren't
+// meaningful in Windows, so nothing is checked there.
+func checkSecretFile(file string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return output.Errorf(
+			codeMissingCredentials,
+			"Can't check secret file '%s': %v",
+			file, err,
+		)
+	}
+	mode := info.Mode().Perm()
+	if mode&0077 != 0 {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: The secret file '%s' has mode %04o, so it can be accessed by "+
+				"other users. Run 'chmod go-rwx %s' and try again.\n",
+			file, mode, file,
+		)
+		return output.Errorf(
+			codeInvalidOption,
+			"Secret file '%s' can be accessed by the group or by other users",
+			file,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the '--status' option, which reports if the
+// stored session can be used without sending any request to the server.
+
+package login
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/times"
+)
+
+// sessionStatus contains the details of the stored session reported by the '--status' option.
+// The expiration time is nil when the session doesn't expire, because the configuration contains
+// credentials that can be used to request new tokens, or because the tokens don't expire.
+type sessionStatus struct {
+	URL       string     `json:"url"`
+	User      string     `json:"user,omitempty"`
+	AccountID string     `json:"account_id,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+	Expires   *time.Time `json:"expires,omitempty"`
+}
+
+// runStatus loads the configuration and reports if it can be used to send authenticated requests.
+// It fails if there is no configuration or if it doesn't contain credentials or valid tokens, so
+// that scripts can check the exit code.
+func runStatus(cmd *cobra.Command) error {
+	// This option can't be combined with the options that are used to log in:
+	var others []string
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed && flag.Name != "status" {
+			others = append(others, "'--"+flag.Name+"'")
+		}
+	})
+	if len(others) > 0 {
+		return output.Errorf(
+			codeConflictingOptions,
+			"Option '--status' can't be used with other options, but got %s",
+			strings.Join(others, ", "),
+		)
+	}
+
+	// From now on failures mean that the session can't be used, not that the command is wrong:
+	cmd.SilenceUsage = true
+
+	// Load the configuration and check that it can be used:
+	cfg, err := config.Load()
+	if err != nil {
+		return output.Errorf(codeConfig, "Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		return output.Errorf(codeNotLoggedIn, "Not logged in, there is no configuration")
+	}
+	armed, reason, err := cfg.Armed()
+	if err != nil {
+		return output.Errorf(codeInvalidToken, "Can't check if tokens have expired: %v", err)
+	}
+	if !armed {
+		return output.Errorf(codeNotLoggedIn, "Not logged in, %s", reason)
+	}
+
+	// Print the details:
+	status := makeStatus(cfg)
+	if output.JSON() {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return output.Errorf(codeOutput, "Can't marshal status: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	printStatus(os.Stdout, status, time.Now())
+	return nil
+}
+
+// makeStatus extracts the details of the session from the configuration and its tokens. Details
+// that can't be extracted are left empty, as they are only informative.
+func makeStatus(cfg *config.Config) *sessionStatus {
+	status := &sessionStatus{
+		URL:  cfg.URL,
+		User: cfg.User,
+	}
+	if status.URL == "" {
+		status.URL = "default"
+	}
+
+	// The refresh token is preferred because it determines how long the session lasts, but it
+	// may be opaque, and then the access token is used instead:
+	var token *jwt.Token
+	for _, text := range []string{cfg.RefreshToken, cfg.AccessToken} {
+		if text == "" {
+			continue
+		}
+		parsed, err := config.ParseToken(text)
+		if err == nil {
+			token = parsed
+			break
+		}
+	}
+	if token != nil {
+		if status.User == "" {
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if ok {
+				status.User, _ = claims["preferred_username"].(string)
+			}
+		}
+		status.AccountID, _ = config.TokenSubject(token)
+		status.Issuer, _ = config.TokenIssuer(token)
+	}
+
+	// Sessions with credentials never expire, as the credentials are used to request new tokens:
+	haveCredentials := cfg.User != "" && cfg.Password != "" ||
+		cfg.ClientID != "" && cfg.ClientSecret != ""
+	if !haveCredentials && token != nil {
+		expiry, err := config.TokenExpiry(token)
+		if err == nil && !expiry.IsZero() {
+			status.Expires = &expiry
+		}
+	}
+	return status
+}
+
+// printStatus writes the details of the session to the given stream in a format suitable for
+// humans.
+func printStatus(stream io.Writer, status *sessionStatus, now time.Time) {
+	fmt.Fprintf(stream, "URL:        %s\n", status.URL)
+	fmt.Fprintf(stream, "User:       %s\n", valueOrUnknown(status.User))
+	fmt.Fprintf(stream, "Account ID: %s\n", valueOrUnknown(status.AccountID))
+	fmt.Fprintf(stream, "Issuer:     %s\n", valueOrUnknown(status.Issuer))
+	if status.Expires != nil {
+		fmt.Fprintf(
+			stream, "Expires:    %s (%s)\n",
+			times.Relative(*status.Expires, now),
+			status.Expires.Local().Format(time.RFC1123),
+		)
+	} else {
+		fmt.Fprintf(stream, "Expires:    never\n")
+	}
+}
+
+// valueOrUnknown returns the given value, or 'unknown' if it is empty.
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -24,6 +24,10 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 )
 
+var args struct {
+	keepServer bool
+}
+
 var Cmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Log out",
@@ -31,7 +35,39 @@ var Cmd = &cobra.Command{
 	RunE:  run,
 }
 
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.keepServer,
+		"keep-server",
+		false,
+		"Remove only the tokens and secrets, keeping the server URL, token URL, client "+
+			"identifier, scopes and insecure setting for the next login.",
+	)
+}
+
 func run(cmd *cobra.Command, argv []string) error {
+	// Clear only the credentials if requested:
+	if args.keepServer {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("Can't load config file: %v", err)
+		}
+		if cfg == nil {
+			return nil
+		}
+		cfg.AccessToken = ""
+		cfg.RefreshToken = ""
+		cfg.User = ""
+		cfg.Password = ""
+		cfg.ClientSecret = ""
+		err = config.Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
+		return nil
+	}
+
 	// Remove the configuration file:
 	err := config.Remove()
 	if err != nil {
@@ -26,9 +26,11 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/tokenurl"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/completion"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/setcredentials"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/get"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/login"
@@ -38,12 +40,20 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/token"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/version"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/whoami"
+	configpkg "github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/output"
 )
 
 var root = &cobra.Command{
-	Use:  "ocm",
-	Long: "Command line tool for api.openshift.com.",
+	Use:               "ocm",
+	Long:              "Command line tool for api.openshift.com.",
+	PersistentPreRunE: preRun,
+
+	// Errors are reported by the main function, because the format depends on the '--output'
+	// option, and that may not have been parsed yet when the flags or arguments are rejected:
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func init() {
@@ -61,6 +71,11 @@ func init() {
 	// Add the command line flags:
 	fs := root.PersistentFlags()
 	flags.AddDebugFlag(fs)
+	flags.AddRequestIDFlag(fs)
+	flags.AddProfileFlag(fs)
+	flags.AddConfigFlag(fs)
+	flags.AddTimeoutFlag(fs)
+	flags.AddOutputFlag(fs)
 
 	// Register the subcommands:
 	root.AddCommand(account.Cmd)
@@ -76,6 +91,34 @@ func init() {
 	root.AddCommand(completion.Cmd)
 	root.AddCommand(whoami.Cmd)
 	root.AddCommand(config.Cmd)
+
+	// Add the option that overrides the URL to all the commands:
+	addURLFlag(root)
+}
+
+// ownURLCommands are the commands that have their own '--url' option, to select the gateway that
+// is stored in the configuration. The option that overrides the URL isn't added to them, as it
+// would hide their own.
+var ownURLCommands = map[*cobra.Command]bool{
+	login.Cmd:          true,
+	setcredentials.Cmd: true,
+	tokenurl.Cmd:       true,
+}
+
+// addURLFlag adds the option that overrides the URL to the given command, if it doesn't have
+// subcommands, or else to all its subcommands, except to the ones that have their own '--url'
+// option.
+func addURLFlag(cmd *cobra.Command) {
+	if ownURLCommands[cmd] {
+		return
+	}
+	if !cmd.HasSubCommands() {
+		flags.AddURLFlag(cmd.Flags())
+		return
+	}
+	for _, sub := range cmd.Commands() {
+		addURLFlag(sub)
+	}
 }
 
 func main() {
@@ -90,7 +133,35 @@ func main() {
 
 	// Execute the root command:
 	root.SetArgs(os.Args[1:])
-	if err = root.Execute(); err != nil {
+	cmd, err := root.ExecuteC()
+
+	// Save the tokens that may have been refreshed while the command was running:
+	configpkg.SaveTokens()
+
+	// Report the connections used while the command was running, if in debug mode:
+	configpkg.LogConnectionStats()
+
+	if err != nil {
+		if output.JSON() {
+			_ = output.PrintError(os.Stdout, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if !cmd.SilenceUsage {
+				fmt.Fprintf(os.Stderr, "%s\n", cmd.UsageString())
+			}
+		}
 		os.Exit(1)
 	}
 }
+
+// preRun checks the global options.
+func preRun(cmd *cobra.Command, argv []string) error {
+	err := output.Check(cmd)
+	if err != nil {
+		return err
+	}
+	if cmd != login.Cmd && cmd != logout.Cmd {
+		configpkg.NudgeDeprecatedAuth(os.Stderr)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	configpkg "github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+func TestMain(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Main")
+}
+
+var _ = Describe("Set credentials", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "ocm")
+		Expect(err).ToNot(HaveOccurred())
+		err = os.Setenv(configpkg.LocationEnv, filepath.Join(dir, "ocm.json"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.Unsetenv(configpkg.LocationEnv)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.RemoveAll(dir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Stores the URL given with the '--url' option", func() {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"typ": "Offline",
+			"iat": time.Now().Unix(),
+		}).SignedString([]byte("secret"))
+		Expect(err).ToNot(HaveOccurred())
+		root.SetArgs([]string{
+			"config", "set-credentials",
+			"--token", token,
+			"--url", "https://api.stage.openshift.com",
+		})
+		_, err = root.ExecuteC()
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := configpkg.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).ToNot(BeNil())
+		Expect(cfg.URL).To(Equal("https://api.stage.openshift.com"))
+	})
+})
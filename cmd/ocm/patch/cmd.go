@@ -25,6 +25,8 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/operation"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
@@ -55,27 +57,15 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Load the configuration file:
-	cfg, err := config.Load()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+		return err
 	}
 
 	// Create the connection:
-	connection, err := cfg.Connection()
+	connection, err := ocm.ConnectionFor(cfg)
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
 
 	// Create and populate the request:
@@ -88,10 +78,13 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Send the request:
-	response, err := request.Send()
+	ctx, cancel := config.RequestContext(connection)
+	response, err := request.SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't send request: %v", err)
 	}
+	operation.Print(response.Header(operation.Header))
 	status := response.Status()
 	body := response.Bytes()
 	if status < 400 {
@@ -103,14 +96,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Can't print body: %v", err)
 	}
 
-	// Save the configuration:
-	cfg.AccessToken, cfg.RefreshToken, err = connection.Tokens()
-	if err != nil {
-		return fmt.Errorf("Can't get tokens: %v", err)
-	}
-	err = config.Save(cfg)
-	if err != nil {
-		return fmt.Errorf("Can't save config file: %v", err)
+	// Save the configuration, unless the URL has been overridden:
+	if config.URLOverride() == "" {
+		ctx, cancel = config.RequestContext(connection)
+		cfg.AccessToken, cfg.RefreshToken, err = connection.TokensContext(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("Can't get tokens: %v", err)
+		}
+		err = config.Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
 	}
 
 	// Bye:
@@ -19,12 +19,16 @@ package post
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/flags"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/operation"
+	"github.com/openshift-online/ocm-cli/pkg/templates"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
@@ -32,6 +36,9 @@ var args struct {
 	parameter []string
 	header    []string
 	body      string
+	template  string
+	set       []string
+	list      bool
 }
 
 var Cmd = &cobra.Command{
@@ -46,52 +53,108 @@ func init() {
 	flags.AddParameterFlag(fs, &args.parameter)
 	flags.AddHeaderFlag(fs, &args.header)
 	flags.AddBodyFlag(fs, &args.body)
+	fs.StringVar(
+		&args.template,
+		"from-template",
+		"",
+		"Name of the template used to generate the request body. Use the "+
+			"'--list-templates' option to see the available templates.",
+	)
+	fs.StringArrayVar(
+		&args.set,
+		"set",
+		nil,
+		"Value used to fill the template, in the 'key=value' format. Can be repeated "+
+			"multiple times to specify multiple values.",
+	)
+	fs.BoolVar(
+		&args.list,
+		"list-templates",
+		false,
+		"List the available request body templates and exit.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	// List the templates if requested:
+	if args.list {
+		for _, tmpl := range templates.List() {
+			fmt.Printf(
+				"%s\n  %s\n  Values: %s\n",
+				tmpl.Name, tmpl.Description, strings.Join(tmpl.Keys, ", "),
+			)
+		}
+		return nil
+	}
+
+	// Fill the template if requested:
+	var templateBody []byte
+	if args.template != "" {
+		if args.body != "" {
+			return fmt.Errorf("Options '--body' and '--from-template' are mutually exclusive")
+		}
+		tmpl := templates.Lookup(args.template)
+		if tmpl == nil {
+			return fmt.Errorf(
+				"Unknown template '%s', use '--list-templates' to see the available ones",
+				args.template,
+			)
+		}
+		values := map[string]string{}
+		for _, item := range args.set {
+			position := strings.Index(item, "=")
+			if position <= 0 {
+				return fmt.Errorf("Value '%s' should be in the 'key=value' format", item)
+			}
+			values[item[:position]] = item[position+1:]
+		}
+		var err error
+		templateBody, err = tmpl.Fill(values)
+		if err != nil {
+			return fmt.Errorf("Can't fill template: %v", err)
+		}
+	} else if len(args.set) > 0 {
+		return fmt.Errorf("Option '--set' can only be used with '--from-template'")
+	}
+
 	path, err := urls.Expand(argv)
 	if err != nil {
 		return fmt.Errorf("Could not create URI: %v", err)
 	}
 
 	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, err := cfg.Armed()
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+		return err
 	}
 
 	// Create the connection:
-	connection, err := cfg.Connection()
+	connection, err := ocm.ConnectionFor(cfg)
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
 
 	// Create and populate the request:
 	request := connection.Post().Path(path)
 	flags.ApplyParameterFlag(request, args.parameter)
 	flags.ApplyHeaderFlag(request, args.parameter)
-	err = flags.ApplyBodyFlag(request, args.body)
-	if err != nil {
-		return fmt.Errorf("Can't read body: %v", err)
+	if templateBody != nil {
+		request.Bytes(templateBody)
+	} else {
+		err = flags.ApplyBodyFlag(request, args.body)
+		if err != nil {
+			return fmt.Errorf("Can't read body: %v", err)
+		}
 	}
 
 	// Send the request:
-	response, err := request.Send()
+	ctx, cancel := config.RequestContext(connection)
+	response, err := request.SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't send request: %v", err)
 	}
+	operation.Print(response.Header(operation.Header))
 	status := response.Status()
 	body := response.Bytes()
 	if status < 400 {
@@ -103,14 +166,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Can't print body: %v", err)
 	}
 
-	// Save the configuration:
-	cfg.AccessToken, cfg.RefreshToken, err = connection.Tokens()
-	if err != nil {
-		return fmt.Errorf("Can't get tokens: %v", err)
-	}
-	err = config.Save(cfg)
-	if err != nil {
-		return fmt.Errorf("Can't save config file: %v", err)
+	// Save the configuration, unless the URL has been overridden:
+	if config.URLOverride() == "" {
+		ctx, cancel = config.RequestContext(connection)
+		cfg.AccessToken, cfg.RefreshToken, err = connection.TokensContext(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("Can't get tokens: %v", err)
+		}
+		err = config.Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
 	}
 
 	// Bye:
@@ -18,14 +18,19 @@ package token
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/shell"
 )
 
 var args struct {
@@ -33,14 +38,18 @@ var args struct {
 	payload   bool
 	signature bool
 	refresh   bool
+	expires   bool
+	field     string
 }
 
 var Cmd = &cobra.Command{
 	Use:   "token",
 	Short: "Generates a token",
-	Long:  "Uses the stored credentials to generate a token.",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "Uses the stored credentials to generate a token. With the global '--output env' " +
+		"option it prints a shell variable assignment for 'OCM_TOKEN', suitable for 'eval' " +
+		"or 'source'.",
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 func init() {
@@ -69,6 +78,21 @@ func init() {
 		false,
 		"Print the refresh token instead of the access token.",
 	)
+	flags.BoolVar(
+		&args.expires,
+		"expires",
+		false,
+		"Print the time left till the token expires, instead of the token.",
+	)
+	flags.StringVar(
+		&args.field,
+		"field",
+		"",
+		"Print the raw value of the top level claim with the given name, instead of the "+
+			"token. For 'sub' the value of the 'account_id' claim is used if there is "+
+			"no 'sub' claim. Fails if the token doesn't contain the claim.",
+	)
+	output.AddEnvFormat(Cmd)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -83,36 +107,37 @@ func run(cmd *cobra.Command, argv []string) error {
 	if args.signature {
 		count++
 	}
-	if count > 1 {
-		return fmt.Errorf("Options '--payload', '--header' and '--signature' are mutually exclusive")
+	if args.expires {
+		count++
 	}
-
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+	if args.field != "" {
+		count++
 	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
+	if count > 1 {
+		return fmt.Errorf("Options '--payload', '--header', '--signature', '--expires' " +
+			"and '--field' are mutually exclusive")
+	}
+	if output.Env() && count > 0 {
+		return fmt.Errorf("Option '--output env' can't be used with '--payload', '--header', " +
+			"'--signature', '--expires' or '--field'")
 	}
 
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, err := cfg.Armed()
+	// Load the configuration file:
+	cfg, err := config.LoadExisting()
 	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
+		return err
 	}
 
 	// Create the connection:
-	connection, err := cfg.Connection()
+	connection, err := ocm.ConnectionFor(cfg)
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
 
 	// Get the tokens:
-	accessToken, refreshToken, err := connection.Tokens()
+	ctx, cancel := config.RequestContext(connection)
+	accessToken, refreshToken, err := connection.TokensContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't get token: %v", err)
 	}
@@ -120,12 +145,62 @@ func run(cmd *cobra.Command, argv []string) error {
 	// Select the token according to the options:
 	selectedToken := accessToken
 	if args.refresh {
+		if refreshToken == "" {
+			return errNoRefreshToken
+		}
 		selectedToken = refreshToken
 	}
 
+	// Print the data:
+	if args.header || args.payload || args.signature {
+		err = printDecoded(selectedToken)
+		if err != nil {
+			return err
+		}
+	} else if args.expires {
+		err = printExpires(selectedToken)
+		if err != nil {
+			return err
+		}
+	} else if args.field != "" {
+		err = printField(selectedToken, args.field)
+		if err != nil {
+			return err
+		}
+	} else if output.Env() {
+		err = shell.Assign(os.Stdout, "OCM_TOKEN", selectedToken)
+		if err != nil {
+			return fmt.Errorf("Can't print token: %v", err)
+		}
+	} else {
+		fmt.Fprintf(os.Stdout, "%s\n", selectedToken)
+	}
+
+	// Save the configuration, unless the URL has been overridden:
+	if config.URLOverride() == "" {
+		cfg.AccessToken = accessToken
+		cfg.RefreshToken = refreshToken
+		err = config.Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
+	}
+
+	// Bye:
+	return nil
+}
+
+// errNoRefreshToken is the error returned when the refresh token is requested but there isn't one.
+var errNoRefreshToken = fmt.Errorf("There is no refresh token, this happens when logging in " +
+	"with an access token or with client credentials, log in with a refresh or offline " +
+	"token to get one")
+
+// printDecoded decodes the given token and prints the part selected by the command line options.
+// This works for refresh tokens too, as the SDK only accepts tokens that are JWT tokens.
+func printDecoded(token string) error {
 	// Parse the token:
 	parser := new(jwt.Parser)
-	_, parts, err := parser.ParseUnverified(selectedToken, jwt.MapClaims{})
+	parsed, parts, err := parser.ParseUnverified(token, jwt.MapClaims{})
 	if err != nil {
 		return fmt.Errorf("Can't parse token: %v", err)
 	}
@@ -143,34 +218,131 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Can't decode signature: %v", err)
 	}
 
-	// Print the data:
-	if args.header {
+	// Print the selected part:
+	switch {
+	case args.header:
 		err = dump.Pretty(os.Stdout, header)
 		if err != nil {
 			return fmt.Errorf("Can't dump header: %v", err)
 		}
-	} else if args.payload {
+	case args.payload:
 		err = dump.Pretty(os.Stdout, payload)
 		if err != nil {
 			return fmt.Errorf("Can't dump payload: %v", err)
 		}
-	} else if args.signature {
+		err = printSummary(parsed)
+		if err != nil {
+			return err
+		}
+	case args.signature:
 		err = dump.Pretty(os.Stdout, signature)
 		if err != nil {
 			return fmt.Errorf("Can't dump signature: %v", err)
 		}
+	}
+	return nil
+}
+
+// printExpires prints the time left till the given token expires, or the time since it expired.
+func printExpires(text string) error {
+	token, err := config.ParseToken(text)
+	if err != nil {
+		return fmt.Errorf("Can't parse token: %v", err)
+	}
+	expiry, err := config.TokenExpiry(token)
+	if err != nil {
+		return fmt.Errorf("Can't extract expiry time from 'exp' claim: %v", err)
+	}
+	if expiry.IsZero() {
+		fmt.Fprintf(os.Stdout, "no expiration\n")
+		return nil
+	}
+	left := time.Until(expiry).Round(time.Second)
+	if left >= 0 {
+		fmt.Fprintf(os.Stdout, "expires in %v\n", left)
 	} else {
-		fmt.Fprintf(os.Stdout, "%s\n", selectedToken)
+		fmt.Fprintf(os.Stdout, "expired %v ago\n", -left)
 	}
+	return nil
+}
 
-	// Save the configuration:
-	cfg.AccessToken = accessToken
-	cfg.RefreshToken = refreshToken
-	err = config.Save(cfg)
+// printField prints the raw value of the given claim of the token. Strings and numbers are printed
+// without quotes or conversions, so that they can be used directly in scripts, and other values
+// are printed as JSON.
+func printField(text string, name string) error {
+	// Parse the token preserving the original text of numbers, as otherwise large values like
+	// the expiration time would be printed in scientific notation:
+	parser := &jwt.Parser{
+		UseJSONNumber: true,
+	}
+	token, _, err := parser.ParseUnverified(text, jwt.MapClaims{})
 	if err != nil {
-		return fmt.Errorf("Can't save config file: %v", err)
+		return fmt.Errorf("Can't parse token: %v", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return fmt.Errorf("Expected map claims but got %T", token.Claims)
 	}
 
-	// Bye:
+	// Get the value of the claim:
+	var value interface{}
+	if name == "sub" {
+		subject, err := config.TokenSubject(token)
+		if err != nil {
+			return fmt.Errorf("Can't extract subject: %v", err)
+		}
+		if subject != "" {
+			value = subject
+		}
+	} else {
+		value = claims[name]
+	}
+	if value == nil {
+		return fmt.Errorf("Token doesn't contain claim '%s'", name)
+	}
+
+	// Print the value:
+	switch typed := value.(type) {
+	case string:
+		fmt.Fprintf(os.Stdout, "%s\n", typed)
+	case json.Number:
+		fmt.Fprintf(os.Stdout, "%s\n", typed.String())
+	default:
+		data, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Errorf("Can't marshal claim '%s': %v", name, err)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	}
 	return nil
 }
+
+// printSummary writes to the standard error the issuer, type and expiration time of the token, in
+// a format easier to read than the raw claims, so that the standard output still contains only the
+// JSON payload.
+func printSummary(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return fmt.Errorf("Expected map claims but got %T", token.Claims)
+	}
+	expiry, err := config.TokenExpiry(token)
+	if err != nil {
+		return fmt.Errorf("Can't extract expiry time from 'exp' claim: %v", err)
+	}
+	expires := "never"
+	if !expiry.IsZero() {
+		expires = expiry.Local().Format(time.RFC1123)
+	}
+	fmt.Fprintf(os.Stderr, "Issuer:  %v\n", valueOrNone(claims["iss"]))
+	fmt.Fprintf(os.Stderr, "Type:    %v\n", valueOrNone(claims["typ"]))
+	fmt.Fprintf(os.Stderr, "Expires: %s\n", expires)
+	return nil
+}
+
+// valueOrNone returns the given claim value, or 'none' if it is missing.
+func valueOrNone(value interface{}) interface{} {
+	if value == nil {
+		return "none"
+	}
+	return value
+}
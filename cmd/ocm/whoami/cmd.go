@@ -18,6 +18,7 @@ package whoami
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -26,47 +27,71 @@ import (
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/shell"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Prints user information",
-	Long:  "Prints user information.",
-	RunE:  run,
+	Long: "Prints user information. With the global '--output env' option it prints shell " +
+		"variable assignments for the account identifier, organization identifier and user " +
+		"name, suitable for 'eval' or 'source'.",
+	RunE: run,
 }
 
-func run(cmd *cobra.Command, argv []string) error {
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
-	}
-	if cfg == nil {
-		return fmt.Errorf("Not logged in, run the 'login' command")
-	}
-
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, err := cfg.Armed()
-	if err != nil {
-		return fmt.Errorf("Can't check if tokens have expired: %v", err)
-	}
-	if !armed {
-		return fmt.Errorf("Tokens have expired, run the 'login' command")
-	}
+func init() {
+	output.AddEnvFormat(Cmd)
+}
 
+func run(cmd *cobra.Command, argv []string) error {
 	// Create the connection:
-	connection, err := cfg.Connection()
+	connection, err := ocm.NewConnection()
 	if err != nil {
-		return fmt.Errorf("Can't create connection: %v", err)
+		return err
 	}
 
 	// Send the request:
+	ctx, cancel := config.RequestContext(connection)
 	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
-		Send()
+		SendContext(ctx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("Can't send request: %v", err)
 	}
 
+	// Find the issuer and client of the access token used for the request:
+	ctx, cancel = config.RequestContext(connection)
+	accessToken, _, err := connection.TokensContext(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Can't get token: %v", err)
+	}
+	issuer, clientID := tokenOrigin(accessToken)
+
+	// Print the shell variables if requested:
+	if output.Env() {
+		account := response.Body()
+		err = shell.Assign(os.Stdout, "OCM_ACCOUNT_ID", account.ID())
+		if err != nil {
+			return err
+		}
+		err = shell.Assign(os.Stdout, "OCM_ORG_ID", account.Organization().ID())
+		if err != nil {
+			return err
+		}
+		err = shell.Assign(os.Stdout, "OCM_USERNAME", account.Username())
+		if err != nil {
+			return err
+		}
+		err = shell.Assign(os.Stdout, "OCM_TOKEN_ISSUER", issuer)
+		if err != nil {
+			return err
+		}
+		return shell.Assign(os.Stdout, "OCM_TOKEN_CLIENT_ID", clientID)
+	}
+
 	// Buffer for pretty output:
 	buf := new(bytes.Buffer)
 
@@ -76,10 +101,16 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to marshal account into JSON encoder: %v", err)
 	}
 
+	// Add the details of the token:
+	body, err := addFields(buf.Bytes(), "token_issuer", issuer, "token_client_id", clientID)
+	if err != nil {
+		return fmt.Errorf("Can't add token details: %v", err)
+	}
+
 	if response.Status() < 400 {
-		err = dump.Pretty(os.Stdout, buf.Bytes())
+		err = dump.Pretty(os.Stdout, body)
 	} else {
-		err = dump.Pretty(os.Stderr, buf.Bytes())
+		err = dump.Pretty(os.Stderr, body)
 	}
 	if err != nil {
 		return fmt.Errorf("Can't print body: %v", err)
@@ -87,3 +118,56 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// tokenOrigin returns the issuer and the client identifier of the given token. When they can't be
+// extracted from the token it returns 'unknown' instead of failing, as they are only informative.
+func tokenOrigin(text string) (issuer string, clientID string) {
+	issuer = "unknown"
+	clientID = "unknown"
+	token, err := config.ParseToken(text)
+	if err != nil {
+		return
+	}
+	value, err := config.TokenIssuer(token)
+	if err == nil && value != "" {
+		issuer = value
+	}
+	value, err = config.TokenClientID(token)
+	if err == nil && value != "" {
+		clientID = value
+	}
+	return
+}
+
+// addFields adds the given names and values to the end of the given JSON object, preserving the
+// order of the existing fields.
+func addFields(object []byte, pairs ...string) (result []byte, err error) {
+	object = bytes.TrimSpace(object)
+	if len(object) < 2 || object[len(object)-1] != '}' {
+		err = fmt.Errorf("expected a JSON object")
+		return
+	}
+	buffer := bytes.NewBuffer(object[:len(object)-1])
+	empty := len(bytes.TrimSpace(object[1:len(object)-1])) == 0
+	for i := 0; i+1 < len(pairs); i += 2 {
+		var name, value []byte
+		name, err = json.Marshal(pairs[i])
+		if err != nil {
+			return
+		}
+		value, err = json.Marshal(pairs[i+1])
+		if err != nil {
+			return
+		}
+		if !empty {
+			buffer.WriteString(",")
+		}
+		empty = false
+		buffer.Write(name)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+	result = buffer.Bytes()
+	return
+}
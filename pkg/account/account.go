@@ -21,35 +21,48 @@ import (
 
 	"github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/search"
 )
 
 // GetRolesFromUser gets all roles a specific user possesses.
 func GetRolesFromUser(account *amv1.Account, conn *sdk.Connection) ([]string, error) {
+	var roles []string
+	bindings, err := GetRoleBindingsFromUser(account, conn)
+	if err != nil {
+		return roles, err
+	}
+	// Save the role ids iff they are not in the list yet:
+	for _, binding := range bindings {
+		if !stringInList(roles, binding.Role().ID()) {
+			roles = append(roles, binding.Role().ID())
+		}
+	}
+	return roles, nil
+}
+
+// GetRoleBindingsFromUser gets all the role bindings of a specific user, including the type of
+// each binding and the organization or subscription that it applies to.
+func GetRoleBindingsFromUser(account *amv1.Account, conn *sdk.Connection) ([]*amv1.RoleBinding,
+	error) {
 
 	pageIndex := 1
-	var roles []string
+	var bindings []*amv1.RoleBinding
 
-	// Get all roles in each role page:
+	// Get all role bindings in each page:
 	for {
 		rolesList := conn.AccountsMgmt().V1().RoleBindings().List().Page(pageIndex)
-		// Format search request:
-		searchRequest := ""
-		searchRequest = fmt.Sprintf("account_id='%s'", account.ID())
-		// Add parameter to search for role with matching user id:
-		rolesList.Parameter("search", searchRequest)
+		// Add parameter to search for role bindings with matching user id:
+		rolesList.Parameter("search", fmt.Sprintf("account_id='%s'", account.ID()))
 		// Get response:
-		response, err := rolesList.Send()
+		ctx, cancel := config.RequestContext(conn)
+		response, err := rolesList.SendContext(ctx)
+		cancel()
 		if err != nil {
-			return roles, fmt.Errorf("Can't retrieve roles: %v", err)
+			return bindings, fmt.Errorf("Can't retrieve roles: %v", err)
 		}
-		// Loop through roles and save their ids
-		// iff it is not in the list yet:
-		response.Items().Each(func(item *amv1.RoleBinding) bool {
-			if !stringInList(roles, item.Role().ID()) {
-				roles = append(roles, item.Role().ID())
-			}
-			return true
-		})
+		bindings = append(bindings, response.Items().Slice()...)
 
 		// Break
 		if response.Size() < 100 {
@@ -58,7 +71,79 @@ func GetRolesFromUser(account *amv1.Account, conn *sdk.Connection) ([]string, er
 
 		pageIndex++
 	}
-	return roles, nil
+	return bindings, nil
+}
+
+// GetAccountID returns the identifier of the account that corresponds to the given owner, which can
+// be the user name of the account or 'me' to indicate the account of the current user.
+func GetAccountID(owner string, conn *sdk.Connection) (string, error) {
+	if owner == "me" {
+		ctx, cancel := config.RequestContext(conn)
+		response, err := conn.AccountsMgmt().V1().CurrentAccount().Get().SendContext(ctx)
+		cancel()
+		if err != nil {
+			return "", fmt.Errorf("Can't retrieve current account: %v", err)
+		}
+		return response.Body().ID(), nil
+	}
+	ctx, cancel := config.RequestContext(conn)
+	response, err := conn.AccountsMgmt().V1().Accounts().List().
+		Size(1).
+		Parameter("search", "username = "+search.Quote(owner)).
+		SendContext(ctx)
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("Can't retrieve account for user '%s': %v", owner, err)
+	}
+	if response.Size() == 0 {
+		return "", fmt.Errorf("There is no account for user '%s'", owner)
+	}
+	return response.Items().Get(0).ID(), nil
+}
+
+// GetSubscriptionsFromCreator gets all the subscriptions created by the given account.
+func GetSubscriptionsFromCreator(accountID string, conn *sdk.Connection) ([]*amv1.Subscription, error) {
+	pageIndex := 1
+	var subscriptions []*amv1.Subscription
+
+	// Get all the subscriptions in each page:
+	for {
+		ctx, cancel := config.RequestContext(conn)
+		response, err := conn.AccountsMgmt().V1().Subscriptions().List().
+			Size(100).
+			Page(pageIndex).
+			Parameter("search", fmt.Sprintf("creator_id='%s'", accountID)).
+			SendContext(ctx)
+		cancel()
+		if err != nil {
+			return subscriptions, fmt.Errorf("Can't retrieve subscriptions: %v", err)
+		}
+		subscriptions = append(subscriptions, response.Items().Slice()...)
+
+		// Break
+		if response.Size() < 100 {
+			break
+		}
+
+		pageIndex++
+	}
+	return subscriptions, nil
+}
+
+// GetClusterIDsFromCreator gets the identifiers of the clusters of all the subscriptions created
+// by the given account.
+func GetClusterIDsFromCreator(accountID string, conn *sdk.Connection) ([]string, error) {
+	subscriptions, err := GetSubscriptionsFromCreator(accountID, conn)
+	if err != nil {
+		return nil, err
+	}
+	var clusterIDs []string
+	for _, item := range subscriptions {
+		if item.ClusterID() != "" && !stringInList(clusterIDs, item.ClusterID()) {
+			clusterIDs = append(clusterIDs, item.ClusterID())
+		}
+	}
+	return clusterIDs, nil
 }
 
 // stringInList returns a bool signifying whether
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the cache that remembers the identifiers of the clusters that have been
+// found by name.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// CacheTTL is the time that the identifier of a cluster found by name is remembered.
+const CacheTTL = 10 * time.Minute
+
+// cacheEntry is the type used to store each name in the cache file.
+type cacheEntry struct {
+	ID      string    `json:"id"`
+	Expires time.Time `json:"expires"`
+}
+
+// GetClusterCached is like GetCluster, but it first checks if the identifier of the cluster with
+// the given name has been saved to the cache file in the last minutes, and it saves it there after
+// finding a cluster by name. The cached identifier is only used if the cluster that it points to
+// still has the same name, so a stale or foreign entry results only in the regular search.
+func GetClusterCached(ctx context.Context, connection *sdk.Connection,
+	key string) (cluster *cmv1.Cluster, err error) {
+	collection := connection.ClustersMgmt().V1().Clusters()
+	cacheKey := connection.URL() + " " + key
+
+	// Try first the identifier from the cache:
+	entries := loadCache()
+	entry, ok := entries[cacheKey]
+	if ok && time.Now().Before(entry.Expires) {
+		response, err := collection.Cluster(entry.ID).Get().SendContext(ctx)
+		if err == nil && response.Body().Name() == key {
+			return response.Body(), nil
+		}
+	}
+
+	// Do the regular search, and remember the identifier if the cluster was found by name:
+	cluster, err = GetCluster(ctx, collection, key)
+	if err != nil {
+		return
+	}
+	if cluster.ID() != key {
+		entries[cacheKey] = cacheEntry{
+			ID:      cluster.ID(),
+			Expires: time.Now().Add(CacheTTL),
+		}
+		saveCache(entries)
+	}
+	return
+}
+
+// cacheLocation returns the location of the cache file.
+func cacheLocation() (path string, err error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		err = fmt.Errorf("can't find home directory, HOME environment variable is empty")
+		return
+	}
+	path = filepath.Join(home, ".ocm-cache.json")
+	return
+}
+
+// loadCache loads the entries of the cache file that haven't expired. The cache is only an
+// optimization, so errors are logged and result in an empty cache.
+func loadCache() map[string]cacheEntry {
+	entries := map[string]cacheEntry{}
+	file, err := cacheLocation()
+	if err != nil {
+		glog.V(1).Infof("Can't find cache file: %v", err)
+		return entries
+	}
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			glog.V(1).Infof("Can't read cache file '%s': %v", file, err)
+		}
+		return entries
+	}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		glog.V(1).Infof("Can't parse cache file '%s': %v", file, err)
+		return map[string]cacheEntry{}
+	}
+	now := time.Now()
+	for key, entry := range entries {
+		if !now.Before(entry.Expires) {
+			delete(entries, key)
+		}
+	}
+	return entries
+}
+
+// saveCache saves the given entries to the cache file. Errors are logged and otherwise ignored.
+func saveCache(entries map[string]cacheEntry) {
+	file, err := cacheLocation()
+	if err != nil {
+		glog.V(1).Infof("Can't find cache file: %v", err)
+		return
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		glog.V(1).Infof("Can't marshal cache: %v", err)
+		return
+	}
+	err = ioutil.WriteFile(file, data, 0600)
+	if err != nil {
+		glog.V(1).Infof("Can't write cache file '%s': %v", file, err)
+	}
+}
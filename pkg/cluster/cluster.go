@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to find clusters.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/search"
+)
+
+// GetCluster finds the cluster that matches the given key. The key can be the identifier of the
+// cluster or its name. It returns an error if there is no such cluster, or if there are several
+// clusters with that name.
+func GetCluster(ctx context.Context, collection *cmv1.ClustersClient,
+	key string) (cluster *cmv1.Cluster, err error) {
+	// Try first to use the key as the identifier of the cluster:
+	response, err := collection.Cluster(key).Get().SendContext(ctx)
+	if err == nil {
+		cluster = response.Body()
+		return
+	}
+	if response == nil || response.Status() != http.StatusNotFound {
+		err = fmt.Errorf("can't retrieve cluster '%s': %v", key, err)
+		return
+	}
+
+	// If it isn't a cluster identifier, then try to use it as the name:
+	listResponse, err := collection.List().
+		Search("name = " + search.Quote(key)).
+		Size(2).
+		SendContext(ctx)
+	if err != nil {
+		err = fmt.Errorf("can't retrieve clusters with name '%s': %v", key, err)
+		return
+	}
+	switch listResponse.Total() {
+	case 0:
+		err = fmt.Errorf("there is no cluster with identifier or name '%s'", key)
+	case 1:
+		cluster = listResponse.Items().Get(0)
+	default:
+		err = fmt.Errorf(
+			"there are %d clusters with name '%s', use the identifier instead",
+			listResponse.Total(), key,
+		)
+	}
+	return
+}
@@ -20,11 +20,15 @@ limitations under the License.
 package config
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -36,76 +40,185 @@ import (
 
 // Config is the type used to store the configuration of the client.
 type Config struct {
-	AccessToken  string   `json:"access_token,omitempty"`
-	ClientID     string   `json:"client_id,omitempty"`
-	ClientSecret string   `json:"client_secret,omitempty"`
-	Insecure     bool     `json:"insecure,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	CAFile       string `json:"ca_file,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+
+	// DeprecatedAuth indicates that the credentials are for the deprecated OpenID server, and
+	// DeprecatedAuthWarned is the last time that the user was reminded to migrate.
+	DeprecatedAuth       bool       `json:"deprecated_auth,omitempty"`
+	DeprecatedAuthWarned *time.Time `json:"deprecated_auth_warned,omitempty"`
+
+	Insecure      bool       `json:"insecure,omitempty"`
+	InsecureSince *time.Time `json:"insecure_since,omitempty"`
+
+	// Keyring indicates that the tokens, the password and the client secret are stored in the
+	// keyring of the operating system, and the configuration file contains only placeholders.
+	Keyring bool `json:"keyring,omitempty"`
+
 	Password     string   `json:"password,omitempty"`
 	RefreshToken string   `json:"refresh_token,omitempty"`
 	Scopes       []string `json:"scopes,omitempty"`
-	TokenURL     string   `json:"token_url,omitempty"`
-	URL          string   `json:"url,omitempty"`
-	User         string   `json:"user,omitempty"`
+
+	// Timeout is the maximum time to wait for each request, using the syntax of Go durations,
+	// for example '30s'. Empty or zero means no timeout.
+	Timeout string `json:"timeout,omitempty"`
+
+	TokenURL string `json:"token_url,omitempty"`
+	URL      string `json:"url,omitempty"`
+	User     string `json:"user,omitempty"`
+
+	// file is the configuration file that this configuration was loaded from with LoadExisting,
+	// so that it can be saved back when the tokens change. It is empty otherwise, for example
+	// when the configuration is going to be modified and saved explicitly by the command.
+	file string
+}
+
+// SaveTokensEnv is the name of the environment variable that controls if the tokens that are
+// refreshed when creating a connection are saved to the configuration file. Set it to 'false' in
+// environments where the configuration file is read only.
+const SaveTokensEnv = "OCM_SAVE_TOKENS"
+
+// refreshMargin is the time before the expiration of the access token when it will be refreshed
+// while creating the connection.
+const refreshMargin = time.Minute
+
+// CurrentVersion is the version of the format of the configuration file written by this version
+// of the tool. Files without version are version 0, and they may not have profiles.
+const CurrentVersion = 1
+
+// configFile is the type used to store the content of the configuration file, which contains one
+// configuration for each profile.
+type configFile struct {
+	Version  int                `json:"version"`
+	Profiles map[string]*Config `json:"profiles"`
 }
 
-// Load loads the configuration from the configuration file. If the configuration file doesn't exist
-// it will return an empty configuration object.
+// Load loads the configuration of the selected profile from the configuration file. If the
+// configuration file doesn't exist, or it doesn't contain the selected profile, it will return
+// nil.
 func Load() (cfg *Config, err error) {
 	file, err := Location()
 	if err != nil {
 		return
 	}
-	_, err = os.Stat(file)
-	if os.IsNotExist(err) {
-		cfg = nil
-		err = nil
-		return
+	cfg, err = LoadFrom(file)
+	return
+}
+
+// LoadExisting is like Load, but instead of returning nil when there is no configuration it
+// returns an error that tells the user to log in first. It is intended for the commands that need
+// an existing configuration, so the error messages are ready to show to the user. Tokens obtained
+// by the connections created from the returned configuration are saved back to the file.
+func LoadExisting() (cfg *Config, err error) {
+	file, err := Location()
+	if err == nil {
+		cfg, err = LoadFrom(file)
 	}
 	if err != nil {
-		err = fmt.Errorf("can't check if config file '%s' exists: %v", file, err)
+		err = fmt.Errorf("Can't load config file: %v", err)
 		return
 	}
-	// #nosec G304
-	data, err := ioutil.ReadFile(file)
-	if err != nil {
-		err = fmt.Errorf("can't read config file '%s': %v", file, err)
+	if cfg == nil {
+		err = notFoundError()
 		return
 	}
-	cfg = new(Config)
-	err = json.Unmarshal(data, cfg)
-	if err != nil {
-		err = fmt.Errorf("can't parse config file '%s': %v", file, err)
+	cfg.file = file
+	return
+}
+
+// notFoundError creates the error returned by LoadExisting when there is no configuration for the
+// selected profile.
+func notFoundError() error {
+	profile := Profile()
+	if profile == DefaultProfile {
+		return fmt.Errorf("No configuration found. Run 'ocm login' first.")
+	}
+	return fmt.Errorf(
+		"No configuration found for profile '%s'. Run 'ocm login --profile %s' first.",
+		profile, profile,
+	)
+}
+
+// LoadFrom is like Load, but it uses the given configuration file instead of the default one.
+func LoadFrom(file string) (cfg *Config, err error) {
+	content, _, err := loadFile(file)
+	if err != nil || content == nil {
 		return
 	}
+	cfg = content.Profiles[Profile()]
+	if cfg != nil {
+		err = resolveSecrets(file, Profile(), cfg)
+		if err != nil {
+			cfg = nil
+		}
+	}
 	return
 }
 
-// Save saves the given configuration to the configuration file.
+// Save saves the given configuration to the selected profile of the configuration file, preserving
+// the rest of the profiles. The configuration file is locked while it is updated, so that
+// concurrent invocations don't lose each other's changes.
 func Save(cfg *Config) error {
 	file, err := Location()
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	return SaveTo(file, cfg)
+}
+
+// SaveTo is like Save, but it uses the given configuration file instead of the default one.
+func SaveTo(file string, cfg *Config) error {
+	unlock, err := lock(file)
 	if err != nil {
-		return fmt.Errorf("can't marshal config: %v", err)
+		return err
 	}
-	err = ioutil.WriteFile(file, data, 0600)
+	defer unlock()
+	content, _, err := loadFile(file)
 	if err != nil {
-		return fmt.Errorf("can't write file '%s': %v", file, err)
+		return err
 	}
-	return nil
+	if content == nil {
+		content = &configFile{
+			Profiles: map[string]*Config{},
+		}
+	}
+	profile := Profile()
+	stored, err := storeSecrets(file, profile, cfg, content.Profiles[profile])
+	if err != nil {
+		return err
+	}
+	content.Profiles[profile] = stored
+	return saveFile(file, content)
 }
 
-// Remove removes the configuration file.
+// Remove removes the selected profile from the configuration file. If no profile remains then the
+// configuration file is removed.
 func Remove() error {
 	file, err := Location()
 	if err != nil {
 		return err
 	}
-	_, err = os.Stat(file)
-	if os.IsNotExist(err) {
-		return nil
+	unlock, err := lock(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	content, _, err := loadFile(file)
+	if err != nil || content == nil {
+		return err
+	}
+	profile := Profile()
+	if cfg := content.Profiles[profile]; cfg != nil && cfg.Keyring {
+		err = removeSecrets(file, profile)
+		if err != nil {
+			return err
+		}
+	}
+	delete(content.Profiles, profile)
+	if len(content.Profiles) > 0 {
+		return saveFile(file, content)
 	}
 	err = os.Remove(file)
 	if err != nil {
@@ -114,20 +227,234 @@ func Remove() error {
 	return nil
 }
 
-// Location returns the location of the configuration file.
-func Location() (path string, err error) {
-	home := os.Getenv("HOME")
-	if home == "" {
-		err = fmt.Errorf("can't find home directory, HOME environment variable is empty")
+// Migrate rewrites the configuration file in the current format if it uses an older one. The
+// returned flag indicates if the file was rewritten.
+func Migrate() (migrated bool, err error) {
+	file, err := Location()
+	if err != nil {
 		return
 	}
-	path = filepath.Join(home, ".ocm.json")
+	unlock, err := lock(file)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	content, migrated, err := loadFile(file)
+	if err != nil || !migrated {
+		return
+	}
+	err = saveFile(file, content)
 	return
 }
 
+// loadFile loads the complete content of the given configuration file. If the file doesn't exist
+// it returns nil. If the file uses an old format it is migrated in memory to the current one, and
+// the returned flag is true. The migrated content is written only when the file is saved.
+func loadFile(file string) (content *configFile, migrated bool, err error) {
+	info, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("can't check if config file '%s' exists: %v", file, err)
+		return
+	}
+	checkPermissions(file, info)
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("can't read config file '%s': %v", file, err)
+		return
+	}
+	content, migrated, err = parseFile(data)
+	if err != nil {
+		err = fmt.Errorf("can't parse config file '%s': %v", file, err)
+		return
+	}
+	if migrated {
+		glog.V(1).Infof(
+			"Config file '%s' uses an old format, it will be migrated to version %d "+
+				"when saved",
+			file, CurrentVersion,
+		)
+	}
+	return
+}
+
+// parseFile parses the content of the configuration file, migrating it to the current version if
+// needed. The returned flag indicates if the content was migrated.
+func parseFile(data []byte) (content *configFile, migrated bool, err error) {
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return
+	}
+	content = new(configFile)
+	_, ok := fields["version"]
+	if !ok {
+		_, ok = fields["profiles"]
+	}
+	if ok {
+		err = json.Unmarshal(data, content)
+		if err != nil {
+			return
+		}
+	} else {
+		cfg := new(Config)
+		err = json.Unmarshal(data, cfg)
+		if err != nil {
+			return
+		}
+		content.Profiles = map[string]*Config{
+			DefaultProfile: cfg,
+		}
+	}
+	if content.Profiles == nil {
+		content.Profiles = map[string]*Config{}
+	}
+	if content.Version > CurrentVersion {
+		err = fmt.Errorf(
+			"version %d is newer than the latest supported version %d, update the tool",
+			content.Version, CurrentVersion,
+		)
+		return
+	}
+	if content.Version < CurrentVersion {
+		migrateFile(content)
+		migrated = true
+	}
+	return
+}
+
+// migrateFile updates the given content of the configuration file to the current version. Version
+// 0 files didn't always contain the URL and the scopes, and the tool used the defaults in that
+// case, so they are explicitly added.
+func migrateFile(content *configFile) {
+	for _, cfg := range content.Profiles {
+		if cfg == nil {
+			continue
+		}
+		if cfg.URL == "" {
+			cfg.URL = sdk.DefaultURL
+		}
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = append([]string{}, sdk.DefaultScopes...)
+		}
+	}
+	content.Version = CurrentVersion
+}
+
+// saveFile writes the complete content of the given configuration file. The content is first
+// written to a temporary file in the same directory, and then that file is renamed, so that the
+// configuration file is never left partially written.
+func saveFile(file string, content *configFile) error {
+	content.Version = CurrentVersion
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal config: %v", err)
+	}
+	return writeFile(file, data)
+}
+
+// writeFile writes the given data to the configuration file, using a temporary file that is then
+// renamed, as explained in saveFile.
+func writeFile(file string, data []byte) error {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("can't create directory for file '%s': %v", file, err)
+	}
+	temp, err := ioutil.TempFile(dir, filepath.Base(file)+".tmp")
+	if err != nil {
+		return fmt.Errorf("can't create temporary file for '%s': %v", file, err)
+	}
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Chmod(0600)
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), file)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("can't write file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// checkPermissions writes a warning if the configuration file can be read by users other than the
+// owner, as it may contain tokens and passwords. Permissions aren't meaningful in Windows, so
+// nothing is checked there. The warning is written only once.
+func checkPermissions(file string, info os.FileInfo) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	permissionsChecked.Do(func() {
+		mode := info.Mode().Perm()
+		if mode&0077 != 0 {
+			fmt.Fprintf(
+				os.Stderr,
+				"WARNING: The config file '%s' has mode %04o, so it can be read by "+
+					"other users. It will be changed to 0600 the next time it is "+
+					"saved, or you can run 'chmod 600 %s'.\n",
+				file, mode, file,
+			)
+		}
+	})
+}
+
+// permissionsChecked is used to check the permissions of the configuration file only once.
+var permissionsChecked sync.Once
+
+// SetInsecure enables or disables insecure communication with the server. When it is enabled the
+// time is recorded, unless it was already enabled, so that it is possible to tell how long the
+// configuration has been insecure.
+func (c *Config) SetInsecure(value bool) {
+	if value && (!c.Insecure || c.InsecureSince == nil) {
+		now := time.Now().UTC()
+		c.InsecureSince = &now
+	}
+	if !value {
+		c.InsecureSince = nil
+	}
+	c.Insecure = value
+}
+
+// Redacted returns a copy of the configuration where the tokens, the password and the client
+// secret have been replaced by a fixed text, so that it can be displayed safely.
+func (c *Config) Redacted() *Config {
+	result := *c
+	redact(&result.AccessToken)
+	redact(&result.RefreshToken)
+	redact(&result.Password)
+	redact(&result.ClientSecret)
+	return &result
+}
+
+// String returns a text representation of the configuration with the secrets redacted, so that
+// they aren't accidentally leaked when the configuration is written to the log.
+func (c *Config) String() string {
+	data, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("can't marshal config: %v", err)
+	}
+	return string(data)
+}
+
+func redact(value *string) {
+	if *value != "" {
+		*value = "***"
+	}
+}
+
 // Armed checks if the configuration contains either credentials or tokens that haven't expired, so
-// that it can be used to perform authenticated requests.
-func (c *Config) Armed() (armed bool, err error) {
+// that it can be used to perform authenticated requests. When it isn't armed the returned reason
+// explains why, in a way suitable for displaying it to the user.
+func (c *Config) Armed() (armed bool, reason string, err error) {
 	if c.User != "" && c.Password != "" {
 		armed = true
 		return
@@ -161,6 +488,16 @@ func (c *Config) Armed() (armed bool, err error) {
 			return
 		}
 	}
+	switch {
+	case c.AccessToken != "" && c.RefreshToken != "":
+		reason = "the access and refresh tokens have expired"
+	case c.AccessToken != "":
+		reason = "the access token has expired"
+	case c.RefreshToken != "":
+		reason = "the refresh token has expired"
+	default:
+		reason = "there are no credentials or tokens"
+	}
 	return
 }
 
@@ -193,7 +530,9 @@ func (c *Config) Connection() (connection *sdk.Connection, err error) {
 	if c.Scopes != nil {
 		builder.Scopes(c.Scopes...)
 	}
-	if c.URL != "" {
+	if urlOverride != "" {
+		builder.URL(urlOverride)
+	} else if c.URL != "" {
 		builder.URL(c.URL)
 	}
 	if c.User != "" || c.Password != "" {
@@ -210,16 +549,94 @@ func (c *Config) Connection() (connection *sdk.Connection, err error) {
 		builder.Tokens(tokens...)
 	}
 	builder.Insecure(c.Insecure)
+	if c.CAFile != "" {
+		var pool *x509.CertPool
+		pool, err = LoadCAs(c.CAFile)
+		if err != nil {
+			return
+		}
+		builder.TrustedCAs(pool)
+	}
+
+	// The SDK doesn't support timeouts, so the commands apply it to the context of each
+	// request instead:
+	timeout, err := c.RequestTimeout()
+	if err != nil {
+		return
+	}
 
 	// Create the connection:
 	connection, err = builder.Build()
 	if err != nil {
 		return
 	}
+	setTimeout(connection, timeout)
+
+	// Refresh the access token if it is about to expire, and save the new tokens so that the
+	// next invocations don't need to do it again. Nothing is saved when the URL has been
+	// overridden, as the tokens may have been issued for a different gateway:
+	if c.file != "" && saveTokensEnabled() && urlOverride == "" {
+		c.refreshTokens(connection)
+		track(c, connection)
+	}
 
 	return
 }
 
+// LoadCAs creates a pool containing the system certificate authorities and the ones of the given
+// PEM file. The file is read every time that a connection is created, so that changes take effect
+// without having to log in again.
+func LoadCAs(file string) (pool *x509.CertPool, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("can't read CA file '%s': %v", file, err)
+		return
+	}
+	pool, err = x509.SystemCertPool()
+	if err != nil {
+		glog.V(1).Infof("Can't load system certificate authorities: %v", err)
+		pool = x509.NewCertPool()
+		err = nil
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		err = fmt.Errorf("CA file '%s' doesn't contain any PEM certificate", file)
+		return
+	}
+	return
+}
+
+// refreshTokens uses the given connection to replace the access token of the configuration if it
+// is about to expire, and then saves the configuration. Failures are only logged, as the
+// connection will try to refresh the tokens again when it is used.
+func (c *Config) refreshTokens(connection *sdk.Connection) {
+	if c.AccessToken == "" || c.RefreshToken == "" {
+		return
+	}
+	expires, left, err := tokenExpiry(c.AccessToken, time.Now())
+	if err != nil || !expires || left > refreshMargin {
+		return
+	}
+	c.syncTokens(connection)
+}
+
+// saveTokensEnabled checks the environment variable that controls if refreshed tokens are saved.
+func saveTokensEnabled() bool {
+	value := os.Getenv(SaveTokensEnv)
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		glog.V(1).Infof(
+			"Ignoring invalid value '%s' of environment variable '%s': %v",
+			value, SaveTokensEnv, err,
+		)
+		return true
+	}
+	return enabled
+}
+
 func tokenExpiry(text string, now time.Time) (expires bool, left time.Duration, err error) {
 	parser := new(jwt.Parser)
 	token, _, err := parser.ParseUnverified(text, jwt.MapClaims{})
@@ -0,0 +1,499 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config")
+}
+
+// makeToken generates a token that expires after the given duration. A zero duration generates
+// a token that never expires.
+func makeToken(life time.Duration) string {
+	claims := jwt.MapClaims{
+		"typ": "Bearer",
+		"exp": 0,
+	}
+	if life != 0 {
+		claims["exp"] = time.Now().Add(life).Unix()
+	}
+	text, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+	return text
+}
+
+// useTempDir creates a temporary directory before each test of the container where it is called,
+// and removes it after the test. It returns a function that calculates paths inside that directory.
+// The default location of the configuration file is also changed to a file inside the directory,
+// so that tests never touch the configuration of the user.
+func useTempDir() func(elems ...string) string {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "config")
+		Expect(err).ToNot(HaveOccurred())
+		location = filepath.Join(dir, "ocm.json")
+	})
+	AfterEach(func() {
+		location = ""
+		err := os.RemoveAll(dir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	return func(elems ...string) string {
+		return filepath.Join(append([]string{dir}, elems...)...)
+	}
+}
+
+var _ = Describe("Armed", func() {
+	DescribeTable(
+		"Configurations",
+		func(makeConfig func() *Config, expectedArmed bool, expectedReason string) {
+			armed, reason, err := makeConfig().Armed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(armed).To(Equal(expectedArmed))
+			Expect(reason).To(Equal(expectedReason))
+		},
+		Entry(
+			"Empty",
+			func() *Config {
+				return &Config{}
+			},
+			false, "there are no credentials or tokens",
+		),
+		Entry(
+			"User and password",
+			func() *Config {
+				return &Config{User: "user", Password: "password"}
+			},
+			true, "",
+		),
+		Entry(
+			"Client credentials",
+			func() *Config {
+				return &Config{ClientID: "id", ClientSecret: "secret"}
+			},
+			true, "",
+		),
+		Entry(
+			"Valid access token",
+			func() *Config {
+				return &Config{AccessToken: makeToken(time.Hour)}
+			},
+			true, "",
+		),
+		Entry(
+			"Expired access token",
+			func() *Config {
+				return &Config{AccessToken: makeToken(-time.Hour)}
+			},
+			false, "the access token has expired",
+		),
+		Entry(
+			"Expired access token and valid refresh token",
+			func() *Config {
+				return &Config{
+					AccessToken:  makeToken(-time.Hour),
+					RefreshToken: makeToken(time.Hour),
+				}
+			},
+			true, "",
+		),
+		Entry(
+			"Expired access and refresh tokens",
+			func() *Config {
+				return &Config{
+					AccessToken:  makeToken(-time.Hour),
+					RefreshToken: makeToken(-time.Hour),
+				}
+			},
+			false, "the access and refresh tokens have expired",
+		),
+		Entry(
+			"Expired refresh token",
+			func() *Config {
+				return &Config{RefreshToken: makeToken(-time.Hour)}
+			},
+			false, "the refresh token has expired",
+		),
+		Entry(
+			"Offline token",
+			func() *Config {
+				return &Config{RefreshToken: makeToken(0)}
+			},
+			true, "",
+		),
+	)
+})
+
+var _ = Describe("Token subject", func() {
+	DescribeTable(
+		"Extracts the subject",
+		func(claims jwt.MapClaims, expected string) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			subject, err := TokenSubject(token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(subject).To(Equal(expected))
+		},
+		Entry(
+			"From 'sub'",
+			jwt.MapClaims{"sub": "my-sub", "account_id": "my-account"},
+			"my-sub",
+		),
+		Entry(
+			"From 'account_id'",
+			jwt.MapClaims{"account_id": "my-account"},
+			"my-account",
+		),
+		Entry(
+			"Missing",
+			jwt.MapClaims{},
+			"",
+		),
+	)
+
+	It("Rejects subject that isn't a string", func() {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": 123})
+		_, err := TokenSubject(token)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Set token", func() {
+	DescribeTable(
+		"Stores the token according to its type",
+		func(claims jwt.MapClaims, expectedAccess bool) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			cfg := &Config{}
+			Expect(cfg.SetToken("my-token", token)).To(Succeed())
+			if expectedAccess {
+				Expect(cfg.AccessToken).To(Equal("my-token"))
+				Expect(cfg.RefreshToken).To(BeEmpty())
+			} else {
+				Expect(cfg.AccessToken).To(BeEmpty())
+				Expect(cfg.RefreshToken).To(Equal("my-token"))
+			}
+		},
+		Entry("Bearer", jwt.MapClaims{"typ": "Bearer"}, true),
+		Entry("Lower case bearer", jwt.MapClaims{"typ": "bearer"}, true),
+		Entry("Refresh", jwt.MapClaims{"typ": "Refresh"}, false),
+		Entry("Upper case refresh", jwt.MapClaims{"typ": "REFRESH"}, false),
+		Entry("Offline", jwt.MapClaims{"typ": "Offline"}, false),
+		Entry("Offline access", jwt.MapClaims{"typ": "offline_access"}, false),
+		Entry("Bearer in 'token_type'", jwt.MapClaims{"token_type": "Bearer"}, true),
+		Entry("Refresh in 'token_type'", jwt.MapClaims{"token_type": "refresh_token"}, false),
+		Entry(
+			"Unknown 'typ' and known 'token_type'",
+			jwt.MapClaims{"typ": "JWT", "token_type": "bearer"},
+			true,
+		),
+	)
+
+	DescribeTable(
+		"Rejects tokens without a known type",
+		func(claims jwt.MapClaims, expected string) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			err := new(Config).SetToken("my-token", token)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expected))
+		},
+		Entry(
+			"Missing",
+			jwt.MapClaims{},
+			"without 'typ' or 'token_type' claim",
+		),
+		Entry(
+			"Unknown",
+			jwt.MapClaims{"typ": "ID"},
+			"found 'ID' in claim 'typ'",
+		),
+		Entry(
+			"Unknown in both claims",
+			jwt.MapClaims{"typ": "ID", "token_type": "magic"},
+			"found 'ID' in claim 'typ' and 'magic' in claim 'token_type'",
+		),
+		Entry(
+			"Not a string",
+			jwt.MapClaims{"typ": 123},
+			"expected string 'typ'",
+		),
+	)
+})
+
+var _ = Describe("Check file", func() {
+	It("Accepts valid file", func() {
+		err := CheckFile([]byte(`{
+			"version": 1,
+			"profiles": {
+				"default": {
+					"url": "https://my.api",
+					"timeout": "30s"
+				}
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	DescribeTable(
+		"Rejects invalid file",
+		func(data string, expected string) {
+			err := CheckFile([]byte(data))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expected))
+		},
+		Entry(
+			"Invalid JSON",
+			`{"version": 1,`,
+			"unexpected end of JSON input",
+		),
+		Entry(
+			"Wrong type",
+			`{"version": 1, "profiles": {"default": {"insecure": "yes"}}}`,
+			"cannot unmarshal string",
+		),
+		Entry(
+			"Newer version",
+			`{"version": 100, "profiles": {}}`,
+			"newer than the latest supported version",
+		),
+		Entry(
+			"Invalid timeout",
+			`{"version": 1, "profiles": {"my": {"timeout": "soon"}}}`,
+			"profile 'my': value 'soon' of setting 'timeout' isn't a valid duration",
+		),
+	)
+})
+
+var _ = Describe("Parse file", func() {
+	It("Migrates a file without profiles to the default profile", func() {
+		content, migrated, err := parseFile([]byte(`{"url": "https://my.api"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeTrue())
+		Expect(content.Profiles).To(HaveLen(1))
+		Expect(content.Profiles).To(HaveKey(DefaultProfile))
+		Expect(content.Profiles[DefaultProfile].URL).To(Equal("https://my.api"))
+	})
+
+	It("Doesn't migrate a file with the current version", func() {
+		content, migrated, err := parseFile([]byte(`{
+			"version": 1,
+			"profiles": {
+				"prod": {"url": "https://my.api"}
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeFalse())
+		Expect(content.Profiles).To(HaveLen(1))
+		Expect(content.Profiles).To(HaveKey("prod"))
+		Expect(content.Profiles["prod"].URL).To(Equal("https://my.api"))
+	})
+
+	It("Migrates a version 0 file", func() {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "v0.json"))
+		Expect(err).ToNot(HaveOccurred())
+		content, migrated, err := parseFile(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeTrue())
+		Expect(content.Version).To(Equal(CurrentVersion))
+		Expect(content.Profiles).To(HaveLen(1))
+		cfg := content.Profiles[DefaultProfile]
+		Expect(cfg).ToNot(BeNil())
+		Expect(cfg.ClientID).To(Equal("cloud-services"))
+		Expect(cfg.RefreshToken).To(Equal("my_refresh"))
+		Expect(cfg.URL).To(Equal(sdk.DefaultURL))
+		Expect(cfg.Scopes).To(Equal(sdk.DefaultScopes))
+	})
+
+	It("Rejects a file with a newer version", func() {
+		_, _, err := parseFile([]byte(`{"version": 1000, "profiles": {}}`))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Redacted", func() {
+	It("Replaces the secrets without changing the original", func() {
+		cfg := &Config{
+			AccessToken:  "my_access",
+			ClientID:     "my_client",
+			ClientSecret: "my_secret",
+			Password:     "my_password",
+			RefreshToken: "my_refresh",
+			URL:          "https://my.api",
+		}
+		redacted := cfg.Redacted()
+		Expect(redacted.AccessToken).To(Equal("***"))
+		Expect(redacted.ClientSecret).To(Equal("***"))
+		Expect(redacted.Password).To(Equal("***"))
+		Expect(redacted.RefreshToken).To(Equal("***"))
+		Expect(redacted.ClientID).To(Equal("my_client"))
+		Expect(redacted.URL).To(Equal("https://my.api"))
+		Expect(cfg.AccessToken).To(Equal("my_access"))
+		Expect(cfg.String()).ToNot(ContainSubstring("my_secret"))
+	})
+})
+
+var _ = Describe("Save", func() {
+	path := useTempDir()
+
+	It("Leaves a valid file when called concurrently", func() {
+		var wait sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wait.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wait.Done()
+				for j := 0; j < 50; j++ {
+					err := Save(&Config{
+						URL: fmt.Sprintf("https://api%d.example.com/%d", i, j),
+					})
+					Expect(err).ToNot(HaveOccurred())
+					cfg, err := Load()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cfg).ToNot(BeNil())
+				}
+			}(i)
+		}
+		wait.Wait()
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(HaveSuffix("/49"))
+	})
+
+	It("Makes the file readable only by the owner", func() {
+		if runtime.GOOS == "windows" {
+			Skip("Permissions aren't supported in Windows")
+		}
+		file := path("ocm", "ocm.json")
+		err := SaveTo(file, &Config{})
+		Expect(err).ToNot(HaveOccurred())
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		info, err = os.Stat(filepath.Dir(file))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+	})
+
+	It("Tells the user to log in when the file doesn't exist", func() {
+		cfg, err := LoadExisting()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("No configuration found. Run 'ocm login' first."))
+		Expect(cfg).To(BeNil())
+	})
+
+	It("Uses the given file instead of the default location", func() {
+		file := path("other.json")
+		err := SaveTo(file, &Config{URL: "https://my.api"})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).To(BeNil())
+		cfg, err = LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).ToNot(BeNil())
+		Expect(cfg.URL).To(Equal("https://my.api"))
+	})
+})
+
+var _ = Describe("Merge scopes", func() {
+	DescribeTable(
+		"Scopes",
+		func(scopes []string, additions []string, expected []string) {
+			Expect(MergeScopes(scopes, additions)).To(Equal(expected))
+		},
+		Entry(
+			"No additions",
+			[]string{"openid"},
+			nil,
+			[]string{"openid"},
+		),
+		Entry(
+			"Appends additions",
+			[]string{"openid"},
+			[]string{"offline_access", "api.iam"},
+			[]string{"openid", "offline_access", "api.iam"},
+		),
+		Entry(
+			"Removes duplicates",
+			[]string{"openid", "openid"},
+			[]string{"api.iam", "openid", "api.iam"},
+			[]string{"openid", "api.iam"},
+		),
+	)
+})
+
+var _ = Describe("Migrate", func() {
+	useTempDir()
+
+	BeforeEach(func() {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "v0.json"))
+		Expect(err).ToNot(HaveOccurred())
+		err = ioutil.WriteFile(location, data, 0600)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Doesn't rewrite the file when loading it", func() {
+		before, err := ioutil.ReadFile(location)
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg).ToNot(BeNil())
+		Expect(cfg.URL).To(Equal(sdk.DefaultURL))
+		after, err := ioutil.ReadFile(location)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after).To(Equal(before))
+	})
+
+	It("Rewrites the file in the current format", func() {
+		migrated, err := Migrate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeTrue())
+		data, err := ioutil.ReadFile(location)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(fmt.Sprintf(`{
+			"version": 1,
+			"profiles": {
+				"default": {
+					"client_id": "cloud-services",
+					"refresh_token": "my_refresh",
+					"scopes": ["openid"],
+					"token_url": "%s",
+					"url": "%s"
+				}
+			}
+		}`, PreferredTokenURL, sdk.DefaultURL)))
+		migrated, err = Migrate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeFalse())
+	})
+})
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to remind users that authenticate with the deprecated
+// OpenID server that they should migrate to the preferred one.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// deprecatedAuthInterval is the minimum time between two reminders about the use of the
+// deprecated OpenID server.
+const deprecatedAuthInterval = 24 * time.Hour
+
+// DeprecatedAuthWarning returns the text of the warning displayed to users that authenticate with
+// the deprecated OpenID server. The given URL is the URL of the API gateway.
+func DeprecatedAuthWarning(gateway string) string {
+	return fmt.Sprintf(
+		"WARNING: You are authenticating with '%s', which is deprecated. Go to '%s' "+
+			"to obtain a new offline access token and then run 'ocm login --token' "+
+			"with it. The current credentials will keep working in the meantime.",
+		DeprecatedIssuer, urls.TokenPage(gateway),
+	)
+}
+
+// NudgeDeprecatedAuth writes to the given stream a reminder to migrate to the preferred OpenID
+// server if the configuration uses the deprecated one. The reminder is written at most once a
+// day, and the time is saved to the configuration file. Failures are only logged, as the reminder
+// shouldn't prevent the command from running.
+func NudgeDeprecatedAuth(stream io.Writer) {
+	cfg, err := Load()
+	if err != nil || cfg == nil || !cfg.DeprecatedAuth {
+		return
+	}
+	now := time.Now().UTC()
+	if cfg.DeprecatedAuthWarned != nil && now.Sub(*cfg.DeprecatedAuthWarned) < deprecatedAuthInterval {
+		return
+	}
+	fmt.Fprintf(stream, "%s\n", DeprecatedAuthWarning(cfg.URL))
+	cfg.DeprecatedAuthWarned = &now
+	err = Save(cfg)
+	if err != nil {
+		glog.V(1).Infof("Can't save time of deprecated authentication warning: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to check and replace the content of the configuration
+// file when it is edited by hand, as done by the 'config edit' command.
+
+package config
+
+import (
+	"fmt"
+)
+
+// CheckFile checks that the given data can be used as the content of the configuration file. It
+// must be valid JSON, the settings must have the right types, and the values that are parsed when
+// creating connections must be valid.
+func CheckFile(data []byte) error {
+	content, _, err := parseFile(data)
+	if err != nil {
+		return err
+	}
+	for name, cfg := range content.Profiles {
+		if cfg == nil {
+			continue
+		}
+		if cfg.Timeout != "" {
+			_, err = parseTimeout(cfg.Timeout)
+			if err != nil {
+				return fmt.Errorf("profile '%s': %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReplaceFile checks the given data with CheckFile and then replaces the complete content of the
+// configuration file with it. The data is written as it is, without migrating it to the current
+// format, so that the changes made by the user are preserved.
+func ReplaceFile(data []byte) error {
+	err := CheckFile(data)
+	if err != nil {
+		return err
+	}
+	file, err := Location()
+	if err != nil {
+		return err
+	}
+	unlock, err := lock(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFile(file, data)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to store the secrets of the configuration in the secret
+// store of the operating system instead of in the configuration file.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// keyringPlaceholder is the value written to the configuration file instead of the secrets that
+// are stored in the keyring.
+const keyringPlaceholder = "@keyring"
+
+// keyringService is the name of the service used to store the secrets in the keyring.
+const keyringService = "ocm-cli"
+
+// keyring is the interface of the secret stores of the operating systems. Keys are unique inside
+// the store used by this tool. Getting or removing a key that doesn't exist isn't an error, get
+// returns an empty value in that case.
+type keyring interface {
+	get(key string) (value string, err error)
+	set(key string, value string) error
+	remove(key string) error
+}
+
+// openKeyring returns the keyring of the operating system. It is replaced by the tests.
+var openKeyring = systemKeyring
+
+// keyringWarned makes sure that the warning about the keyring not being available is written only
+// once.
+var keyringWarned sync.Once
+
+// secrets returns pointers to the fields of the configuration that are stored in the keyring, keyed
+// by their names.
+func (c *Config) secrets() map[string]*string {
+	return map[string]*string{
+		"access_token":  &c.AccessToken,
+		"client_secret": &c.ClientSecret,
+		"password":      &c.Password,
+		"refresh_token": &c.RefreshToken,
+	}
+}
+
+// keyringKey calculates the key used to store a secret of the given profile of the given
+// configuration file.
+func keyringKey(file string, profile string, name string) string {
+	return fmt.Sprintf("%s:%s:%s", file, profile, name)
+}
+
+// storeSecrets stores the secrets of the given configuration in the keyring, if it uses it, and
+// returns the configuration that should be written to the file, with the secrets replaced by
+// placeholders. If the keyring isn't available, or it rejects any of the secrets, it writes a
+// warning and returns a copy of the configuration that doesn't use the keyring, so the secrets
+// are written to the file. The secrets of the previous version of the configuration are removed
+// from the keyring if it no longer uses it.
+func storeSecrets(file string, profile string, cfg *Config, previous *Config) (stored *Config,
+	err error) {
+	stored = cfg
+	if !cfg.Keyring {
+		if previous != nil && previous.Keyring {
+			err = removeSecrets(file, profile)
+		}
+		return
+	}
+	ring, err := openKeyring()
+	if err != nil {
+		stored = keyringFallback(file, cfg, fmt.Errorf("the keyring isn't available: %v", err))
+		err = nil
+		return
+	}
+	result := *cfg
+	for name, secret := range result.secrets() {
+		key := keyringKey(file, profile, name)
+		if *secret == "" {
+			err = ring.remove(key)
+		} else {
+			err = ring.set(key, *secret)
+			*secret = keyringPlaceholder
+		}
+		if err != nil {
+			// Don't leave in the keyring the secrets that were already stored, as they will
+			// be written to the file:
+			for other := range result.secrets() {
+				_ = ring.remove(keyringKey(file, profile, other))
+			}
+			stored = keyringFallback(
+				file, cfg,
+				fmt.Errorf("can't store '%s' in the keyring: %v", name, err),
+			)
+			err = nil
+			return
+		}
+	}
+	stored = &result
+	return
+}
+
+// keyringFallback writes a warning explaining why the keyring can't be used, and returns a copy of
+// the given configuration that doesn't use it.
+func keyringFallback(file string, cfg *Config, reason error) *Config {
+	keyringWarned.Do(func() {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: The secrets will be stored in the config file '%s' because %v\n",
+			file, reason,
+		)
+	})
+	result := *cfg
+	result.Keyring = false
+	return &result
+}
+
+// resolveSecrets replaces the placeholders of the given configuration with the secrets stored in
+// the keyring.
+func resolveSecrets(file string, profile string, cfg *Config) error {
+	var ring keyring
+	for name, secret := range cfg.secrets() {
+		if *secret != keyringPlaceholder {
+			continue
+		}
+		if ring == nil {
+			var err error
+			ring, err = openKeyring()
+			if err != nil {
+				return fmt.Errorf(
+					"'%s' is stored in the keyring, but it isn't available: %v",
+					name, err,
+				)
+			}
+		}
+		value, err := ring.get(keyringKey(file, profile, name))
+		if err != nil {
+			return fmt.Errorf("can't get '%s' from the keyring: %v", name, err)
+		}
+		*secret = value
+	}
+	return nil
+}
+
+// removeSecrets removes from the keyring all the secrets of the given profile.
+func removeSecrets(file string, profile string) error {
+	ring, err := openKeyring()
+	if err != nil {
+		return nil
+	}
+	for name := range new(Config).secrets() {
+		err = ring.remove(keyringKey(file, profile, name))
+		if err != nil {
+			return fmt.Errorf("can't remove '%s' from the keyring: %v", name, err)
+		}
+	}
+	return nil
+}
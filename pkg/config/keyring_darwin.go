@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the keyring for macOS, which uses the 'security'
+// command to access the Keychain.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFound is the exit code of the 'security' command when the item doesn't exist.
+const securityNotFound = 44
+
+// keychain is the keyring that uses the 'security' command.
+type keychain struct {
+	path string
+}
+
+// systemKeyring returns the keyring that uses the 'security' command.
+func systemKeyring() (ring keyring, err error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		err = fmt.Errorf("can't find the 'security' command: %v", err)
+		return
+	}
+	ring = &keychain{
+		path: path,
+	}
+	return
+}
+
+func (k *keychain) get(key string) (value string, err error) {
+	output, err := k.run("", "find-generic-password", "-s", keyringService, "-a", key, "-w")
+	if err != nil {
+		if notFound(err) {
+			err = nil
+		}
+		return
+	}
+	value = strings.TrimSuffix(output, "\n")
+	return
+}
+
+// set uses the interactive mode of the 'security' command, reading the command from the standard
+// input, so that the secret isn't visible in the list of processes.
+func (k *keychain) set(key string, value string) error {
+	command := fmt.Sprintf(
+		"add-generic-password -U -s %s -a %s -w %s\n",
+		quote(keyringService), quote(key), quote(value),
+	)
+	_, err := k.run(command, "-i")
+	return err
+}
+
+func (k *keychain) remove(key string) error {
+	_, err := k.run("", "delete-generic-password", "-s", keyringService, "-a", key)
+	if notFound(err) {
+		err = nil
+	}
+	return err
+}
+
+// run runs the 'security' command with the given input and arguments, and returns the standard
+// output.
+func (k *keychain) run(input string, args ...string) (output string, err error) {
+	var stdout bytes.Buffer
+	// #nosec G204
+	cmd := exec.Command(k.path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	err = cmd.Run()
+	output = stdout.String()
+	return
+}
+
+// notFound checks if the given error is the result of the 'security' command not finding the
+// item.
+func notFound(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	return ok && exitErr.ExitCode() == securityNotFound
+}
+
+// quote puts the given text in double quotes, escaping the characters that have a special meaning
+// inside them for the interactive mode of the 'security' command.
+func quote(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(text) + `"`
+}
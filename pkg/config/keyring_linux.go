@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the keyring for Linux, which uses the 'secret-tool'
+// command to access the Secret Service, as provided by GNOME Keyring or KWallet.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretTool is the keyring that uses the 'secret-tool' command.
+type secretTool struct {
+	path string
+}
+
+// systemKeyring returns the keyring that uses the 'secret-tool' command, or an error if that
+// command isn't installed.
+func systemKeyring() (ring keyring, err error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		err = fmt.Errorf("can't find the 'secret-tool' command: %v", err)
+		return
+	}
+	ring = &secretTool{
+		path: path,
+	}
+	return
+}
+
+func (s *secretTool) get(key string) (value string, err error) {
+	output, err := s.run("", "lookup", "service", keyringService, "account", key)
+	if err != nil {
+		// The command fails without writing anything when the key doesn't exist:
+		if _, ok := err.(*exec.ExitError); ok && output == "" {
+			err = nil
+		}
+		return
+	}
+	value = output
+	return
+}
+
+func (s *secretTool) set(key string, value string) error {
+	_, err := s.run(
+		value,
+		"store",
+		"--label", fmt.Sprintf("%s %s", keyringService, key),
+		"service", keyringService,
+		"account", key,
+	)
+	return err
+}
+
+func (s *secretTool) remove(key string) error {
+	_, err := s.run("", "clear", "service", keyringService, "account", key)
+	return err
+}
+
+// run runs the 'secret-tool' command with the given input and arguments, and returns the standard
+// output. The secrets are always passed in the input, so that they aren't visible in the list of
+// processes.
+func (s *secretTool) run(input string, args ...string) (output string, err error) {
+	var stdout, stderr bytes.Buffer
+	// #nosec G204
+	cmd := exec.Command(s.path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	output = stdout.String()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return
+}
@@ -0,0 +1,34 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the keyring for the platforms that don't have a
+// supported secret store.
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// systemKeyring always fails, as there is no supported keyring in this platform.
+func systemKeyring() (ring keyring, err error) {
+	err = fmt.Errorf("there is no supported keyring in platform '%s'", runtime.GOOS)
+	return
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// memoryKeyring is a keyring that stores the secrets in memory.
+type memoryKeyring map[string]string
+
+func (m memoryKeyring) get(key string) (value string, err error) {
+	value = m[key]
+	return
+}
+
+func (m memoryKeyring) set(key string, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m memoryKeyring) remove(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// failingKeyring is a keyring that rejects the refresh token, like the Credential Manager of
+// Windows does with values that are too long.
+type failingKeyring struct {
+	memoryKeyring
+}
+
+func (f failingKeyring) set(key string, value string) error {
+	if strings.HasSuffix(key, ":refresh_token") {
+		return fmt.Errorf("value is too long")
+	}
+	return f.memoryKeyring.set(key, value)
+}
+
+var _ = Describe("Keyring", func() {
+	useTempDir()
+
+	var ring memoryKeyring
+
+	BeforeEach(func() {
+		ring = memoryKeyring{}
+		openKeyring = func() (keyring, error) {
+			return ring, nil
+		}
+	})
+
+	AfterEach(func() {
+		openKeyring = systemKeyring
+	})
+
+	It("Stores the secrets in the keyring", func() {
+		err := Save(&Config{
+			Keyring:      true,
+			RefreshToken: "my_refresh",
+			Password:     "my_password",
+			User:         "my_user",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(location)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).ToNot(ContainSubstring("my_refresh"))
+		Expect(string(data)).ToNot(ContainSubstring("my_password"))
+		Expect(string(data)).To(ContainSubstring("my_user"))
+		Expect(ring).To(HaveLen(2))
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my_refresh"))
+		Expect(cfg.Password).To(Equal("my_password"))
+	})
+
+	It("Stores the secrets in the file if the keyring isn't available", func() {
+		openKeyring = func() (keyring, error) {
+			return nil, fmt.Errorf("no keyring")
+		}
+		err := Save(&Config{
+			Keyring:      true,
+			RefreshToken: "my_refresh",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my_refresh"))
+		Expect(cfg.Keyring).To(BeFalse())
+	})
+
+	It("Stores the secrets in the file if the keyring rejects them", func() {
+		openKeyring = func() (keyring, error) {
+			return failingKeyring{ring}, nil
+		}
+		err := Save(&Config{
+			Keyring:      true,
+			RefreshToken: "my_refresh",
+			Password:     "my_password",
+			User:         "my_user",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ring).To(BeEmpty())
+		data, err := ioutil.ReadFile(location)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).ToNot(ContainSubstring(keyringPlaceholder))
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my_refresh"))
+		Expect(cfg.Password).To(Equal("my_password"))
+		Expect(cfg.Keyring).To(BeFalse())
+	})
+
+	It("Removes the secrets when the keyring is no longer used", func() {
+		err := Save(&Config{
+			Keyring:      true,
+			RefreshToken: "my_refresh",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ring).To(HaveLen(1))
+		err = Save(&Config{
+			RefreshToken: "my_refresh",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ring).To(BeEmpty())
+	})
+
+	It("Removes the secrets when the profile is removed", func() {
+		err := Save(&Config{
+			Keyring:      true,
+			RefreshToken: "my_refresh",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		err = Remove()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ring).To(BeEmpty())
+	})
+})
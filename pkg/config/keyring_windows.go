@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the keyring for Windows, which uses the Credential
+// Manager.
+
+package config
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Constants of the Credential Manager API:
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	credMaxBlobSize         = 5 * 512
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// Functions of the Credential Manager API:
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// credential is the equivalent of the CREDENTIALW structure of the Credential Manager API.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager is the keyring that uses the Credential Manager.
+type credentialManager struct {
+}
+
+// systemKeyring returns the keyring that uses the Credential Manager.
+func systemKeyring() (ring keyring, err error) {
+	err = advapi32.Load()
+	if err != nil {
+		err = fmt.Errorf("can't load the Credential Manager: %v", err)
+		return
+	}
+	ring = &credentialManager{}
+	return
+}
+
+func (m *credentialManager) get(key string) (value string, err error) {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return
+	}
+	var result *credential
+	code, _, callErr := procCredRead.Call(
+		uintptr(unsafe.Pointer(target)),
+		credTypeGeneric,
+		0,
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if code == 0 {
+		if callErr != errorNotFound {
+			err = callErr
+		}
+		return
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(result))) // #nosec G104
+	if result.CredentialBlobSize > 0 {
+		blob := (*[1 << 20]byte)(unsafe.Pointer(result.CredentialBlob))[:result.CredentialBlobSize]
+		value = string(blob)
+	}
+	return
+}
+
+func (m *credentialManager) set(key string, value string) error {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return err
+	}
+	blob := []byte(value)
+	if len(blob) > credMaxBlobSize {
+		return fmt.Errorf(
+			"value is %d bytes long, but the Credential Manager only accepts %d",
+			len(blob), credMaxBlobSize,
+		)
+	}
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	code, _, callErr := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if code == 0 {
+		return callErr
+	}
+	return nil
+}
+
+func (m *credentialManager) remove(key string) error {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return err
+	}
+	code, _, callErr := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if code == 0 && callErr != errorNotFound {
+		return callErr
+	}
+	return nil
+}
+
+// credentialTarget calculates the name of the credential used to store the given key.
+func credentialTarget(key string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(keyringService + ":" + key)
+}
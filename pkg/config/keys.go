@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to get and set the settings of the configuration by name,
+// as done by the 'config get' and 'config set' commands.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keys contains the names of the settings, in the order that they should be displayed. The names
+// are the same used in the configuration file.
+var keys = []string{
+	"access_token",
+	"ca_file",
+	"client_id",
+	"client_secret",
+	"insecure",
+	"insecure_since",
+	"password",
+	"refresh_token",
+	"scopes",
+	"timeout",
+	"token_url",
+	"url",
+	"user",
+}
+
+// Keys returns the names of the settings that can be used with the Get and Set methods.
+func Keys() []string {
+	result := make([]string, len(keys))
+	copy(result, keys)
+	return result
+}
+
+// Get returns the value of the setting with the given name, converted to text.
+func (c *Config) Get(key string) (value string, err error) {
+	switch key {
+	case "access_token":
+		value = c.AccessToken
+	case "ca_file":
+		value = c.CAFile
+	case "client_id":
+		value = c.ClientID
+	case "client_secret":
+		value = c.ClientSecret
+	case "insecure":
+		value = strconv.FormatBool(c.Insecure)
+	case "insecure_since":
+		if c.InsecureSince != nil {
+			value = c.InsecureSince.Format(time.RFC3339)
+		}
+	case "password":
+		value = c.Password
+	case "refresh_token":
+		value = c.RefreshToken
+	case "scopes":
+		value = strings.Join(c.Scopes, ",")
+	case "timeout":
+		value = c.Timeout
+	case "token_url":
+		value = c.TokenURL
+	case "url":
+		value = c.URL
+	case "user":
+		value = c.User
+	default:
+		err = unknownKey(key)
+	}
+	return
+}
+
+// Set changes the value of the setting with the given name. The value of the 'scopes' setting is a
+// comma separated list.
+func (c *Config) Set(key string, value string) error {
+	switch key {
+	case "access_token":
+		c.AccessToken = value
+	case "ca_file":
+		c.CAFile = value
+	case "client_id":
+		c.ClientID = value
+	case "client_secret":
+		c.ClientSecret = value
+	case "insecure":
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("value '%s' of setting 'insecure' isn't a boolean", value)
+		}
+		c.SetInsecure(insecure)
+	case "insecure_since":
+		return fmt.Errorf("setting 'insecure_since' can't be changed, it is updated " +
+			"automatically when 'insecure' changes")
+	case "password":
+		c.Password = value
+	case "refresh_token":
+		c.RefreshToken = value
+	case "scopes":
+		c.Scopes = nil
+		for _, scope := range strings.Split(value, ",") {
+			scope = strings.TrimSpace(scope)
+			if scope != "" {
+				c.Scopes = append(c.Scopes, scope)
+			}
+		}
+	case "timeout":
+		if value != "" {
+			_, err := parseTimeout(value)
+			if err != nil {
+				return err
+			}
+		}
+		c.Timeout = value
+	case "token_url":
+		c.TokenURL = value
+	case "url":
+		c.URL = value
+	case "user":
+		c.User = value
+	default:
+		return unknownKey(key)
+	}
+	return nil
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf(
+		"unknown setting '%s', valid settings are %s",
+		key, strings.Join(keys, ", "),
+	)
+}
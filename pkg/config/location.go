@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to calculate the location of the configuration file,
+// including the '--config' command line option.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+)
+
+// LocationEnv is the name of the environment variable that contains the location of the
+// configuration file when the '--config' command line option isn't used.
+const LocationEnv = "OCM_CONFIG"
+
+// AddLocationFlag adds the flag that sets the location of the configuration file to the given set
+// of command line flags.
+func AddLocationFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&location,
+		"config",
+		"",
+		"Location of the configuration file. If not given the value of the "+LocationEnv+" "+
+			"environment variable will be used. If that is empty then the file will be "+
+			"'ocm/ocm.json' inside the XDG_CONFIG_HOME directory, or '.ocm.json' in the "+
+			"home directory if it already exists, or '.config/ocm/ocm.json' in the home "+
+			"directory.",
+	)
+}
+
+// Location returns the location of the configuration file. The location given in the command line
+// takes precedence, then the OCM_CONFIG environment variable, then the XDG_CONFIG_HOME directory.
+// If none of those is set then the '.ocm.json' file of the home directory is used if it exists,
+// for compatibility with older versions, and '.config/ocm/ocm.json' otherwise.
+func Location() (path string, err error) {
+	if location != "" {
+		path = location
+		return
+	}
+	path = os.Getenv(LocationEnv)
+	if path != "" {
+		return
+	}
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg != "" {
+		path = filepath.Join(xdg, "ocm", "ocm.json")
+		return
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		err = fmt.Errorf("can't find home directory, HOME environment variable is empty")
+		return
+	}
+	path = filepath.Join(home, ".ocm.json")
+	_, err = os.Stat(path)
+	if err == nil {
+		return
+	}
+	if !os.IsNotExist(err) {
+		err = fmt.Errorf("can't check if config file '%s' exists: %v", path, err)
+		return
+	}
+	err = nil
+	path = filepath.Join(home, ".config", "ocm", "ocm.json")
+	return
+}
+
+// location is the location of the configuration file given in the command line.
+var location string
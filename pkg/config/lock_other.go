@@ -0,0 +1,30 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the lock of the configuration file for platforms that
+// don't support advisory locks. In those platforms the configuration file is still replaced
+// atomically when it is saved, but concurrent updates may overwrite each other.
+
+package config
+
+// lock does nothing, as advisory locks aren't supported in this platform.
+func lock(file string) (unlock func(), err error) {
+	unlock = func() {}
+	return
+}
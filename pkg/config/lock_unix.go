@@ -0,0 +1,60 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the lock of the configuration file for platforms that
+// support advisory locks.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lock takes an exclusive advisory lock on the lock file that corresponds to the given
+// configuration file, waiting till it is available. The lock file is separate from the
+// configuration file because the configuration file is replaced when it is saved. The returned
+// function releases the lock.
+func lock(file string) (unlock func(), err error) {
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		err = fmt.Errorf("can't create directory for file '%s': %v", file, err)
+		return
+	}
+	name := file + ".lock"
+	// #nosec G304
+	handle, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		err = fmt.Errorf("can't open lock file '%s': %v", name, err)
+		return
+	}
+	err = syscall.Flock(int(handle.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		_ = handle.Close()
+		err = fmt.Errorf("can't lock file '%s': %v", name, err)
+		return
+	}
+	unlock = func() {
+		_ = syscall.Flock(int(handle.Fd()), syscall.LOCK_UN)
+		_ = handle.Close()
+	}
+	return
+}
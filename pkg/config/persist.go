@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to save the tokens that the connections obtain while the
+// commands run, for example when the server rotates the refresh token each time that it is used.
+
+package config
+
+import (
+	"sync"
+
+	"github.com/golang/glog"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// trackedConnection is a connection created from a configuration loaded from a file, whose tokens
+// will be saved back to that file if they change.
+type trackedConnection struct {
+	cfg        *Config
+	connection *sdk.Connection
+}
+
+// tracked contains the connections whose tokens will be saved by SaveTokens.
+var (
+	tracked      []trackedConnection
+	trackedMutex sync.Mutex
+)
+
+// tokensChanged is called when the tokens of a connection are different to the ones of the
+// configuration that it was created from, after updating the configuration. It saves the
+// configuration to the file it was loaded from.
+var tokensChanged = func(cfg *Config) error {
+	return SaveTo(cfg.file, cfg)
+}
+
+// track adds the given connection to the set of connections whose tokens will be saved by
+// SaveTokens.
+func track(cfg *Config, connection *sdk.Connection) {
+	trackedMutex.Lock()
+	defer trackedMutex.Unlock()
+	tracked = append(tracked, trackedConnection{
+		cfg:        cfg,
+		connection: connection,
+	})
+}
+
+// SaveTokens saves to the configuration file the tokens of the connections created since the
+// previous call that have changed. This should be called when the command finishes, even if the
+// connections have already been closed, so that tokens refreshed or rotated by the server while
+// the command was running aren't lost. Failures are only logged.
+func SaveTokens() {
+	trackedMutex.Lock()
+	connections := tracked
+	tracked = nil
+	trackedMutex.Unlock()
+	for _, item := range connections {
+		item.cfg.syncTokens(item.connection)
+	}
+}
+
+// syncTokens gets the current tokens of the given connection, refreshing them if needed, and if
+// they are different to the ones of the configuration it updates the configuration and calls the
+// tokensChanged hook. The timeout is taken from the configuration, as the connection may have been
+// closed already.
+func (c *Config) syncTokens(connection *sdk.Connection) {
+	timeout, err := c.RequestTimeout()
+	if err != nil {
+		glog.V(1).Infof("Can't get request timeout: %v", err)
+		return
+	}
+	ctx, cancel := timeoutContext(timeout)
+	defer cancel()
+	accessToken, refreshToken, err := connection.TokensContext(ctx)
+	if err != nil {
+		glog.V(1).Infof("Can't get tokens: %v", err)
+		return
+	}
+	if accessToken == c.AccessToken && refreshToken == c.RefreshToken {
+		return
+	}
+	if refreshToken != c.RefreshToken {
+		glog.V(1).Infof("Refresh token has been rotated")
+	}
+	c.AccessToken = accessToken
+	c.RefreshToken = refreshToken
+	err = tokensChanged(c)
+	if err != nil {
+		glog.V(1).Infof("Can't save tokens: %v", err)
+	}
+}
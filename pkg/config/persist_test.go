@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// makeRefreshToken generates a refresh token that expires after the given duration.
+func makeRefreshToken(life time.Duration) string {
+	claims := jwt.MapClaims{
+		"typ": "Refresh",
+		"exp": time.Now().Add(life).Unix(),
+	}
+	text, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+	return text
+}
+
+var _ = Describe("Save tokens", func() {
+	useTempDir()
+
+	var server *httptest.Server
+	var rotated string
+
+	BeforeEach(func() {
+		rotated = makeRefreshToken(20 * time.Hour)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(
+				w,
+				`{"access_token": "%s", "refresh_token": "%s"}`,
+				makeToken(time.Hour), rotated,
+			)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Saves the rotated refresh token", func() {
+		err := Save(&Config{
+			URL:          server.URL,
+			TokenURL:     server.URL + "/token",
+			RefreshToken: makeRefreshToken(10 * time.Hour),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := LoadExisting()
+		Expect(err).ToNot(HaveOccurred())
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = connection.Tokens()
+		Expect(err).ToNot(HaveOccurred())
+		err = Close(connection)
+		Expect(err).ToNot(HaveOccurred())
+		SaveTokens()
+		cfg, err = Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal(rotated))
+		Expect(cfg.AccessToken).ToNot(BeEmpty())
+	})
+
+	It("Doesn't save the tokens of configurations that weren't loaded", func() {
+		err := Save(&Config{
+			URL:          server.URL,
+			TokenURL:     server.URL + "/token",
+			RefreshToken: makeRefreshToken(10 * time.Hour),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		original := cfg.RefreshToken
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = connection.Tokens()
+		Expect(err).ToNot(HaveOccurred())
+		SaveTokens()
+		cfg, err = Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal(original))
+	})
+})
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--profile' command line option.
+
+package config
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultProfile is the name of the profile used when no profile is selected with the
+// '--profile' command line option or the OCM_PROFILE environment variable.
+const DefaultProfile = "default"
+
+// ProfileEnv is the name of the environment variable that selects the profile when the
+// '--profile' command line option isn't used.
+const ProfileEnv = "OCM_PROFILE"
+
+// AddProfileFlag adds the profile flag to the given set of command line flags.
+func AddProfileFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&profile,
+		"profile",
+		"",
+		"Name of the profile of the configuration file to use. If not given the value "+
+			"of the "+ProfileEnv+" environment variable will be used, and if that "+
+			"is empty then the '"+DefaultProfile+"' profile will be used.",
+	)
+}
+
+// Profile returns the name of the selected profile.
+func Profile() string {
+	if profile != "" {
+		return profile
+	}
+	value := os.Getenv(ProfileEnv)
+	if value != "" {
+		return value
+	}
+	return DefaultProfile
+}
+
+// profile is the name of the profile given in the command line.
+var profile string
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to retry the requests sent to the OpenID token endpoint
+// when they fail because of transient errors.
+
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// retryDelay is the delay before the first retry. It is doubled for each subsequent retry.
+var retryDelay = time.Second
+
+// RetryTokens gets the tokens from the given connection, like the Tokens method of the connection,
+// but retrying up to the given number of times, with exponential backoff, when the request to the
+// token endpoint fails because of a network error or a 5xx response. Other errors, for example
+// rejected credentials, are returned immediately. The delay between retries is never longer than
+// the given maximum.
+func RetryTokens(connection *sdk.Connection, retries int,
+	maxDelay time.Duration) (accessToken string, refreshToken string, err error) {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := RequestContext(connection)
+		accessToken, refreshToken, err = connection.TokensContext(ctx)
+		cancel()
+		if err == nil || attempt >= retries || !retryableError(err) {
+			return
+		}
+		if maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
+		glog.V(1).Infof(
+			"Can't get token, will retry in %v (%d of %d): %v",
+			delay, attempt+1, retries, err,
+		)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// retryableError checks if the given error returned by the SDK when requesting tokens is
+// transient. The SDK doesn't return typed errors, so this is based on the text of the message.
+func retryableError(err error) bool {
+	message := err.Error()
+	return strings.HasPrefix(message, "can't send request") ||
+		strings.HasPrefix(message, "can't read response") ||
+		strings.HasPrefix(message, "token response status is: 5")
+}
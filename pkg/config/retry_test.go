@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retry tokens", func() {
+	var server *httptest.Server
+	var statuses []int
+	var requests int
+
+	BeforeEach(func() {
+		retryDelay = time.Millisecond
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := http.StatusOK
+			if requests < len(statuses) {
+				status = statuses[requests]
+			}
+			requests++
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(
+				w,
+				`{"access_token": "%s", "refresh_token": "%s"}`,
+				makeToken(time.Hour), makeToken(10*time.Hour),
+			)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		retryDelay = time.Second
+	})
+
+	makeConfig := func() *Config {
+		return &Config{
+			URL:          server.URL,
+			TokenURL:     server.URL + "/token",
+			ClientID:     "my-client",
+			ClientSecret: "my-secret",
+		}
+	}
+
+	It("Retries after service unavailable", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+		connection, err := makeConfig().Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(connection)
+		accessToken, _, err := RetryTokens(connection, 3, time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(accessToken).ToNot(BeEmpty())
+		Expect(requests).To(Equal(3))
+	})
+
+	It("Gives up after the given number of retries", func() {
+		statuses = []int{
+			http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable,
+		}
+		connection, err := makeConfig().Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(connection)
+		_, _, err = RetryTokens(connection, 2, time.Second)
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal(3))
+	})
+
+	It("Doesn't retry after unauthorized", func() {
+		statuses = []int{http.StatusUnauthorized}
+		connection, err := makeConfig().Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(connection)
+		_, _, err = RetryTokens(connection, 3, time.Second)
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal(1))
+	})
+})
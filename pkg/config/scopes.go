@@ -0,0 +1,34 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// MergeScopes returns the given scopes followed by the given additional scopes, removing the
+// duplicates and keeping the order of the first occurrence of each scope.
+func MergeScopes(scopes []string, additions []string) []string {
+	result := make([]string, 0, len(scopes)+len(additions))
+	seen := map[string]bool{}
+	for _, list := range [][]string{scopes, additions} {
+		for _, scope := range list {
+			if seen[scope] {
+				continue
+			}
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to count the HTTP connections opened and reused by the
+// requests sent with the contexts returned by the RequestContext function.
+
+package config
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+
+	"github.com/openshift-online/ocm-cli/pkg/debug"
+)
+
+// traceConnections adds to the given context a trace that counts the connections used by the
+// request. It only does it when the debug mode is enabled, otherwise it returns the context
+// unchanged.
+func traceConnections(ctx context.Context) context.Context {
+	if !debug.Enabled() {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddInt64(&reusedConnections, 1)
+			} else {
+				atomic.AddInt64(&openedConnections, 1)
+			}
+		},
+	})
+}
+
+// LogConnectionStats writes to the log the number of connections that have been opened and reused
+// while running the command. It does nothing if the debug mode isn't enabled or no request has
+// been sent.
+func LogConnectionStats() {
+	if !debug.Enabled() {
+		return
+	}
+	opened := atomic.LoadInt64(&openedConnections)
+	reused := atomic.LoadInt64(&reusedConnections)
+	if opened+reused == 0 {
+		return
+	}
+	glog.Infof("Connections opened: %d, connections reused: %d", opened, reused)
+}
+
+// openedConnections and reusedConnections are the number of connections opened and reused by
+// the requests sent so far.
+var (
+	openedConnections int64
+	reusedConnections int64
+)
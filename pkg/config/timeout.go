@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--timeout' command line option and the
+// 'timeout' setting of the configuration.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/pflag"
+)
+
+// AddTimeoutFlag adds the timeout flag to the given set of command line flags.
+func AddTimeoutFlag(flags *pflag.FlagSet) {
+	flags.DurationVar(
+		&timeoutValue,
+		"timeout",
+		0,
+		"Maximum time to wait for each request sent to the server, for example '30s'. "+
+			"If not given the value of the 'timeout' setting of the configuration will "+
+			"be used. Zero means no timeout.",
+	)
+	timeoutFlag = flags.Lookup("timeout")
+}
+
+// RequestTimeout returns the timeout for the requests sent with connections created from this
+// configuration. The value of the '--timeout' command line option takes precedence over the
+// 'timeout' setting. Zero means no timeout.
+func (c *Config) RequestTimeout() (timeout time.Duration, err error) {
+	if timeoutFlag != nil && timeoutFlag.Changed {
+		timeout = timeoutValue
+	} else if c.Timeout != "" {
+		timeout, err = parseTimeout(c.Timeout)
+	}
+	return
+}
+
+// RequestContext returns the context that should be used to send one request, or one request for
+// tokens, with the given connection. It is cancelled when the timeout of the configuration used to
+// create the connection expires. For connections that weren't created from a configuration only
+// the '--timeout' command line option is used. The returned function must be called when the
+// request finishes, to release the resources of the context.
+func RequestContext(connection *sdk.Connection) (ctx context.Context, cancel context.CancelFunc) {
+	timeoutsMutex.Lock()
+	timeout, ok := timeouts[connection]
+	timeoutsMutex.Unlock()
+	if !ok && timeoutFlag != nil && timeoutFlag.Changed {
+		timeout = timeoutValue
+	}
+	return timeoutContext(timeout)
+}
+
+// timeoutContext returns a context for one request that is cancelled when the given timeout
+// expires. Zero means no timeout.
+func timeoutContext(timeout time.Duration) (ctx context.Context, cancel context.CancelFunc) {
+	ctx = traceConnections(context.Background())
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Close closes the given connection and forgets its request timeout. Connections created with the
+// Connection method of the configuration should be closed with this function instead of with
+// their Close method.
+func Close(connection *sdk.Connection) error {
+	timeoutsMutex.Lock()
+	delete(timeouts, connection)
+	timeoutsMutex.Unlock()
+	return connection.Close()
+}
+
+// parseTimeout parses the value of the 'timeout' setting, which uses the syntax of Go durations.
+func parseTimeout(value string) (timeout time.Duration, err error) {
+	timeout, err = time.ParseDuration(value)
+	if err != nil {
+		err = fmt.Errorf("value '%s' of setting 'timeout' isn't a valid duration", value)
+		return
+	}
+	if timeout < 0 {
+		err = fmt.Errorf("value '%s' of setting 'timeout' can't be negative", value)
+	}
+	return
+}
+
+// timeoutValue is the value of the '--timeout' command line option, and timeoutFlag is the
+// option itself, used to check if it has been given.
+var (
+	timeoutValue time.Duration
+	timeoutFlag  *pflag.Flag
+)
+
+// timeouts contains the request timeouts of the open connections created from configurations. The
+// SDK doesn't support timeouts, so they are kept here and applied to the context of each request.
+// Entries are removed by the Close function.
+var (
+	timeouts      = map[*sdk.Connection]time.Duration{}
+	timeoutsMutex sync.Mutex
+)
+
+// setTimeout saves the request timeout of the given connection.
+func setTimeout(connection *sdk.Connection, timeout time.Duration) {
+	timeoutsMutex.Lock()
+	defer timeoutsMutex.Unlock()
+	timeouts[connection] = timeout
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timeout", func() {
+	var server *httptest.Server
+	var release chan struct{}
+
+	BeforeEach(func() {
+		release = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Wait till the test finishes or the client gives up:
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		}))
+	})
+
+	AfterEach(func() {
+		close(release)
+		server.Close()
+	})
+
+	It("Cancels requests that take longer than the timeout", func() {
+		cfg := &Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+			Timeout:     "100ms",
+		}
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(connection)
+		ctx, cancel := RequestContext(connection)
+		defer cancel()
+		start := time.Now()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1").SendContext(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("deadline exceeded"))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("Doesn't set a deadline without timeout", func() {
+		cfg := &Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+		}
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(connection)
+		ctx, cancel := RequestContext(connection)
+		defer cancel()
+		_, ok := ctx.Deadline()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Uses the timeout of the configuration of each connection", func() {
+		slow, err := (&Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+			Timeout:     "1h",
+		}).Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(slow)
+		fast, err := (&Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+			Timeout:     "1s",
+		}).Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer Close(fast)
+		slowCtx, slowCancel := RequestContext(slow)
+		defer slowCancel()
+		deadline, ok := slowCtx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(time.Until(deadline)).To(BeNumerically(">", time.Minute))
+		fastCtx, fastCancel := RequestContext(fast)
+		defer fastCancel()
+		deadline, ok = fastCtx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(time.Until(deadline)).To(BeNumerically("<=", time.Second))
+	})
+
+	It("Forgets the timeout when the connection is closed", func() {
+		cfg := &Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+			Timeout:     "1h",
+		}
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timeouts).To(HaveKey(connection))
+		err = Close(connection)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timeouts).ToNot(HaveKey(connection))
+	})
+
+	It("Rejects invalid timeout when creating the connection", func() {
+		cfg := &Config{
+			URL:         server.URL,
+			AccessToken: makeToken(time.Hour),
+			Timeout:     "soon",
+		}
+		_, err := cfg.Connection()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("isn't a valid duration"))
+	})
+
+	DescribeTable(
+		"Set rejects invalid values",
+		func(value string, expected string) {
+			cfg := &Config{}
+			err := cfg.Set("timeout", value)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expected))
+		},
+		Entry("Without unit", "30", "isn't a valid duration"),
+		Entry("Negative", "-1s", "can't be negative"),
+	)
+})
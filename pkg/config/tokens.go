@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to put tokens given by the user in the configuration.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Preferred OpenID details:
+const (
+	// #nosec G101
+	PreferredTokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
+	PreferredClientID = "cloud-services"
+)
+
+// Deprecated OpenID details used only when trying to authenticate with a user name and a password
+// or with a token issued by the deprecated OpenID server:
+const (
+	// #nosec G101
+	DeprecatedTokenURL = "https://developers.redhat.com/auth/realms/rhd/protocol/openid-connect/token"
+	DeprecatedClientID = "ocm"
+	DeprecatedIssuer   = "developers.redhat.com"
+)
+
+// ParseToken parses the given token, without verifying the signature.
+func ParseToken(text string) (token *jwt.Token, err error) {
+	parser := new(jwt.Parser)
+	token, _, err = parser.ParseUnverified(text, jwt.MapClaims{})
+	return
+}
+
+// TokenDefaults returns the OpenID token URL and client identifier that should be used with the
+// given token when they aren't explicitly provided by the user. These are the deprecated ones if
+// the token was issued by the deprecated OpenID server, and the preferred ones otherwise.
+func TokenDefaults(token *jwt.Token) (tokenURL string, clientID string, err error) {
+	tokenURL = PreferredTokenURL
+	clientID = PreferredClientID
+	issuerURL, err := tokenIssuer(token)
+	if err != nil {
+		err = fmt.Errorf("can't get token issuer: %v", err)
+		return
+	}
+	if issuerURL != nil && strings.EqualFold(issuerURL.Hostname(), DeprecatedIssuer) {
+		tokenURL = DeprecatedTokenURL
+		clientID = DeprecatedClientID
+	}
+	return
+}
+
+// SetToken puts the given token in the place of the configuration that corresponds to its type.
+func (c *Config) SetToken(text string, token *jwt.Token) error {
+	typ, err := tokenType(token)
+	if err != nil {
+		return fmt.Errorf("can't extract token type: %v", err)
+	}
+	switch typ {
+	case "Bearer":
+		c.AccessToken = text
+	case "Refresh", "Offline":
+		c.RefreshToken = text
+	default:
+		return fmt.Errorf("don't know how to handle token without 'typ' or 'token_type' claim")
+	}
+	return nil
+}
+
+// TokenIssueTime extracts the value of the `iat` claim. It returns a zero time if there is no such
+// claim.
+func TokenIssueTime(token *jwt.Token) (issued time.Time, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	claim, ok := claims["iat"]
+	if !ok {
+		return
+	}
+	value, ok := claim.(float64)
+	if !ok {
+		err = fmt.Errorf("expected floating point 'iat' but got %T", claim)
+		return
+	}
+	issued = time.Unix(int64(value), 0)
+	return
+}
+
+// TokenExpiry extracts the value of the `exp` claim. It returns a zero time if there is no such
+// claim or if its value is zero, which means that the token doesn't expire.
+func TokenExpiry(token *jwt.Token) (expiry time.Time, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	claim, ok := claims["exp"]
+	if !ok {
+		return
+	}
+	value, ok := claim.(float64)
+	if !ok {
+		err = fmt.Errorf("expected floating point 'exp' but got %T", claim)
+		return
+	}
+	if value != 0 {
+		expiry = time.Unix(int64(value), 0)
+	}
+	return
+}
+
+// TokenIssuer returns the value of the `iss` claim, or the empty string if there is no such claim.
+func TokenIssuer(token *jwt.Token) (issuer string, err error) {
+	issuerURL, err := tokenIssuer(token)
+	if err != nil || issuerURL == nil {
+		return
+	}
+	issuer = issuerURL.String()
+	return
+}
+
+// TokenClientID returns the identifier of the OpenID client that requested the token. This is
+// taken from the `azp` claim, or from the `client_id` claim if there is no `azp` claim. It returns
+// the empty string if there is none of those claims.
+func TokenClientID(token *jwt.Token) (clientID string, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	for _, name := range []string{"azp", "client_id"} {
+		claim, ok := claims[name]
+		if !ok {
+			continue
+		}
+		value, ok := claim.(string)
+		if !ok {
+			err = fmt.Errorf("expected string '%s' but got %T", name, claim)
+			return
+		}
+		clientID = value
+		return
+	}
+	return
+}
+
+// TokenSubject returns the identifier of the account that the token was issued for. This is taken
+// from the `sub` claim, or from the `account_id` claim if there is no `sub` claim. It returns the
+// empty string if there is none of those claims.
+func TokenSubject(token *jwt.Token) (subject string, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	for _, name := range []string{"sub", "account_id"} {
+		claim, ok := claims[name]
+		if !ok {
+			continue
+		}
+		value, ok := claim.(string)
+		if !ok {
+			err = fmt.Errorf("expected string '%s' but got %T", name, claim)
+			return
+		}
+		subject = value
+		return
+	}
+	return
+}
+
+// tokenIssuer extracts the value of the `iss` claim. It then returns tha value as a URL, or nil if
+// there is no such claim.
+func tokenIssuer(token *jwt.Token) (issuer *url.URL, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	claim, ok := claims["iss"]
+	if !ok {
+		return
+	}
+	value, ok := claim.(string)
+	if !ok {
+		err = fmt.Errorf("expected string 'iss' but got %T", claim)
+		return
+	}
+	issuer, err = url.Parse(value)
+	return
+}
+
+// tokenType extracts the type of the token from the `typ` claim, or from the `token_type` claim
+// used by some identity providers. The comparison is case insensitive and synonyms are accepted,
+// so the returned value is always one of `Bearer`, `Refresh` or `Offline`, or the empty string if
+// there is no such claim. Types that aren't known are reported as an error.
+func tokenType(token *jwt.Token) (typ string, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	var found []string
+	for _, name := range tokenTypeClaims {
+		claim, ok := claims[name]
+		if !ok {
+			continue
+		}
+		value, ok := claim.(string)
+		if !ok {
+			err = fmt.Errorf("expected string '%s' but got %T", name, claim)
+			return
+		}
+		typ, ok = tokenTypes[strings.ToLower(value)]
+		if ok {
+			return
+		}
+		found = append(found, fmt.Sprintf("'%s' in claim '%s'", value, name))
+	}
+	if len(found) > 0 {
+		err = fmt.Errorf("unknown token type, found %s", strings.Join(found, " and "))
+	}
+	return
+}
+
+// tokenTypeClaims are the names of the claims that may contain the type of the token, in the
+// order that they are checked.
+var tokenTypeClaims = []string{
+	"typ",
+	"token_type",
+}
+
+// tokenTypes maps the lower case values of the type claims to the types of tokens.
+var tokenTypes = map[string]string{
+	"bearer":         "Bearer",
+	"access":         "Bearer",
+	"access_token":   "Bearer",
+	"refresh":        "Refresh",
+	"refresh_token":  "Refresh",
+	"offline":        "Offline",
+	"offline_access": "Offline",
+}
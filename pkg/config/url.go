@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the global '--url' command line option, which
+// sends the requests of one command to a different API gateway.
+
+package config
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddURLFlag adds the flag that overrides the URL of the API gateway to the given set of command
+// line flags. It shouldn't be added to commands that have their own '--url' option, like 'login'.
+func AddURLFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&urlOverride,
+		"url",
+		"",
+		"URL of the API gateway to use for this command instead of the one stored in the "+
+			"configuration. The stored credentials are used, so they must be valid for "+
+			"that gateway, and nothing is saved to the configuration file.",
+	)
+}
+
+// URLOverride returns the URL given with the global '--url' option, or the empty string if it
+// wasn't used.
+func URLOverride() string {
+	return urlOverride
+}
+
+// urlOverride is the value of the global '--url' command line option.
+var urlOverride string
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to check that a configuration can be used to create a
+// connection.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Validate checks that the configuration contains a valid URL, at most one kind of complete
+// credentials, and tokens that can be parsed. Tokens are allowed together with the credentials,
+// as they are usually obtained with them. When there are no credentials at least one of the
+// tokens must not have expired. All the problems found are reported in the returned error.
+func (c *Config) Validate() error {
+	var problems []string
+
+	// Check the URL:
+	if c.URL == "" {
+		problems = append(problems, "the URL is missing")
+	} else {
+		problem := checkURL(c.URL)
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	// Check the credentials:
+	if c.User != "" && c.Password == "" {
+		problems = append(problems, "the user name is present but the password is missing")
+	}
+	if c.Password != "" && c.User == "" {
+		problems = append(problems, "the password is present but the user name is missing")
+	}
+	if c.ClientSecret != "" && c.ClientID == "" {
+		problems = append(
+			problems,
+			"the client secret is present but the client identifier is missing",
+		)
+	}
+	if (c.User != "" || c.Password != "") && c.ClientSecret != "" {
+		problems = append(
+			problems,
+			"both a user name and password and a client identifier and secret are present, "+
+				"but only one kind of credentials can be used",
+		)
+	}
+	haveCredentials := c.User != "" && c.Password != "" ||
+		c.ClientID != "" && c.ClientSecret != ""
+
+	// Check the tokens:
+	tokensParsed := true
+	if c.AccessToken != "" {
+		_, err := ParseToken(c.AccessToken)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("the access token isn't valid: %v", err))
+			tokensParsed = false
+		}
+	}
+	if c.RefreshToken != "" {
+		_, err := ParseToken(c.RefreshToken)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("the refresh token isn't valid: %v", err))
+			tokensParsed = false
+		}
+	}
+
+	// Without credentials the tokens are the only way to authenticate, so they must not have
+	// expired:
+	if !haveCredentials && tokensParsed {
+		armed, reason, err := c.Armed()
+		switch {
+		case err != nil:
+			problems = append(
+				problems,
+				fmt.Sprintf("can't check if tokens have expired: %v", err),
+			)
+		case !armed:
+			problems = append(problems, reason)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkURL checks that the given text is an absolute URL that can be used to connect to the
+// server. It returns a description of the problem, or the empty string if there is no problem.
+func checkURL(text string) string {
+	parsed, err := url.Parse(text)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("the URL '%s' can't be parsed: %v", text, err)
+	case parsed.Scheme != "http" && parsed.Scheme != "https":
+		return fmt.Sprintf("the URL '%s' should use the 'http' or 'https' scheme", text)
+	case parsed.Hostname() == "":
+		return fmt.Sprintf("the URL '%s' doesn't contain a host name", text)
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	DescribeTable(
+		"Valid",
+		func(makeConfig func() *Config) {
+			Expect(makeConfig().Validate()).To(Succeed())
+		},
+		Entry(
+			"User and password",
+			func() *Config {
+				return &Config{URL: "https://my.api", User: "user", Password: "password"}
+			},
+		),
+		Entry(
+			"Client credentials",
+			func() *Config {
+				return &Config{URL: "https://my.api", ClientID: "id", ClientSecret: "secret"}
+			},
+		),
+		Entry(
+			"Access token",
+			func() *Config {
+				return &Config{URL: "https://my.api", AccessToken: makeToken(time.Hour)}
+			},
+		),
+		Entry(
+			"Refresh token",
+			func() *Config {
+				return &Config{URL: "https://my.api", RefreshToken: makeToken(time.Hour)}
+			},
+		),
+		Entry(
+			"Offline token",
+			func() *Config {
+				return &Config{URL: "https://my.api", RefreshToken: makeToken(0)}
+			},
+		),
+		Entry(
+			"Expired access token and valid refresh token",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					AccessToken:  makeToken(-time.Hour),
+					RefreshToken: makeToken(time.Hour),
+				}
+			},
+		),
+		Entry(
+			"Expired tokens obtained with user and password",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					User:         "user",
+					Password:     "password",
+					AccessToken:  makeToken(-time.Hour),
+					RefreshToken: makeToken(-time.Hour),
+				}
+			},
+		),
+		Entry(
+			"Token and client identifier without secret",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					ClientID:     "id",
+					RefreshToken: makeToken(time.Hour),
+				}
+			},
+		),
+		Entry(
+			"URL with port",
+			func() *Config {
+				return &Config{
+					URL:         "http://localhost:8000",
+					AccessToken: makeToken(time.Hour),
+				}
+			},
+		),
+	)
+
+	DescribeTable(
+		"Invalid",
+		func(makeConfig func() *Config, expected ...string) {
+			err := makeConfig().Validate()
+			Expect(err).To(HaveOccurred())
+			for _, text := range expected {
+				Expect(err.Error()).To(ContainSubstring(text))
+			}
+		},
+		Entry(
+			"Empty",
+			func() *Config {
+				return &Config{}
+			},
+			"the URL is missing",
+			"there are no credentials or tokens",
+		),
+		Entry(
+			"Missing URL",
+			func() *Config {
+				return &Config{User: "user", Password: "password"}
+			},
+			"the URL is missing",
+		),
+		Entry(
+			"URL without scheme",
+			func() *Config {
+				return &Config{URL: "my.api", User: "user", Password: "password"}
+			},
+			"the URL 'my.api' should use the 'http' or 'https' scheme",
+		),
+		Entry(
+			"URL with unsupported scheme",
+			func() *Config {
+				return &Config{URL: "ftp://my.api", User: "user", Password: "password"}
+			},
+			"the URL 'ftp://my.api' should use the 'http' or 'https' scheme",
+		),
+		Entry(
+			"URL without host",
+			func() *Config {
+				return &Config{URL: "https://", User: "user", Password: "password"}
+			},
+			"the URL 'https://' doesn't contain a host name",
+		),
+		Entry(
+			"URL that can't be parsed",
+			func() *Config {
+				return &Config{URL: "https://my.api/%zz", User: "user", Password: "password"}
+			},
+			"the URL 'https://my.api/%zz' can't be parsed",
+		),
+		Entry(
+			"User without password",
+			func() *Config {
+				return &Config{URL: "https://my.api", User: "user"}
+			},
+			"the user name is present but the password is missing",
+			"there are no credentials or tokens",
+		),
+		Entry(
+			"Password without user",
+			func() *Config {
+				return &Config{URL: "https://my.api", Password: "password"}
+			},
+			"the password is present but the user name is missing",
+		),
+		Entry(
+			"Client secret without identifier",
+			func() *Config {
+				return &Config{URL: "https://my.api", ClientSecret: "secret"}
+			},
+			"the client secret is present but the client identifier is missing",
+		),
+		Entry(
+			"User and password and client credentials",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					User:         "user",
+					Password:     "password",
+					ClientID:     "id",
+					ClientSecret: "secret",
+				}
+			},
+			"only one kind of credentials can be used",
+		),
+		Entry(
+			"Access token that can't be parsed",
+			func() *Config {
+				return &Config{URL: "https://my.api", AccessToken: "junk"}
+			},
+			"the access token isn't valid",
+		),
+		Entry(
+			"Refresh token that can't be parsed",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					User:         "user",
+					Password:     "password",
+					RefreshToken: "junk",
+				}
+			},
+			"the refresh token isn't valid",
+		),
+		Entry(
+			"Expired access token",
+			func() *Config {
+				return &Config{URL: "https://my.api", AccessToken: makeToken(-time.Hour)}
+			},
+			"the access token has expired",
+		),
+		Entry(
+			"Expired access and refresh tokens",
+			func() *Config {
+				return &Config{
+					URL:          "https://my.api",
+					AccessToken:  makeToken(-time.Hour),
+					RefreshToken: makeToken(-time.Hour),
+				}
+			},
+			"the access and refresh tokens have expired",
+		),
+		Entry(
+			"Several problems",
+			func() *Config {
+				return &Config{
+					URL:          "ftp://my.api",
+					ClientSecret: "secret",
+					AccessToken:  "junk",
+				}
+			},
+			"the URL 'ftp://my.api' should use the 'http' or 'https' scheme; ",
+			"the client secret is present but the client identifier is missing; ",
+			"the access token isn't valid",
+		),
+	)
+})
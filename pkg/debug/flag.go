@@ -28,7 +28,8 @@ func AddFlag(flags *pflag.FlagSet) {
 		&enabled,
 		"debug",
 		false,
-		"Enable debug mode.",
+		"Enable debug mode. The details of the HTTP requests and responses are written to the "+
+			"standard error output, with the authorization headers and the tokens redacted.",
 	)
 }
 
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to dump the differences between two JSON documents.
+
+package dump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Diff dumps to the given stream the fields that are different in the current JSON document and
+// in the previous one, one per line and sorted by path, for example '.status.state' or
+// '.items[0].id'. Fields that have been added are prefixed with '+' and fields that have been
+// removed with '-'. Fields that have changed are dumped twice, first the old value prefixed with
+// '-' and then the new one prefixed with '+'. If any of the documents isn't a valid JSON document
+// the current one is dumped unchanged.
+func Diff(stream io.Writer, previous, current []byte) error {
+	oldFields, err := flattenJSON(previous)
+	if err != nil {
+		return dumpBytes(stream, current)
+	}
+	newFields, err := flattenJSON(current)
+	if err != nil {
+		return dumpBytes(stream, current)
+	}
+	paths := make([]string, 0, len(oldFields)+len(newFields))
+	for path := range oldFields {
+		paths = append(paths, path)
+	}
+	for path := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		oldValue, inOld := oldFields[path]
+		newValue, inNew := newFields[path]
+		if inOld && inNew && oldValue == newValue {
+			continue
+		}
+		if inOld {
+			_, err = fmt.Fprintf(stream, "- %s: %s\n", path, oldValue)
+			if err != nil {
+				return err
+			}
+		}
+		if inNew {
+			_, err = fmt.Fprintf(stream, "+ %s: %s\n", path, newValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flattenJSON parses the given JSON document and returns a map containing the path of each scalar
+// field, and of each empty object or array, and its value encoded as JSON.
+func flattenJSON(body []byte) (fields map[string]string, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data interface{}
+	err = decoder.Decode(&data)
+	if err != nil {
+		return
+	}
+	fields = map[string]string{}
+	err = flatten("", data, fields)
+	return
+}
+
+func flatten(path string, data interface{}, fields map[string]string) error {
+	switch typed := data.(type) {
+	case map[string]interface{}:
+		if len(typed) > 0 {
+			for key, value := range typed {
+				err := flatten(path+"."+key, value, fields)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if len(typed) > 0 {
+			for i, value := range typed {
+				err := flatten(path+"["+strconv.Itoa(i)+"]", value, fields)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if path == "" {
+		path = "."
+	}
+	value, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	fields[path] = string(value)
+	return nil
+}
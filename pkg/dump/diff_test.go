@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dump
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestDump(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dump")
+}
+
+var _ = Describe("Diff", func() {
+	DescribeTable(
+		"Documents",
+		func(previous, current, expected string) {
+			buffer := &bytes.Buffer{}
+			err := Diff(buffer, []byte(previous), []byte(current))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(Equal(expected))
+		},
+		Entry(
+			"Equal",
+			`{"id": "123", "state": "ready"}`,
+			`{"state": "ready", "id": "123"}`,
+			"",
+		),
+		Entry(
+			"Changed field",
+			`{"id": "123", "status": {"state": "installing"}}`,
+			`{"id": "123", "status": {"state": "ready"}}`,
+			"- .status.state: \"installing\"\n"+
+				"+ .status.state: \"ready\"\n",
+		),
+		Entry(
+			"Added and removed fields",
+			`{"id": "123", "old": true}`,
+			`{"id": "123", "new": 1}`,
+			"+ .new: 1\n"+
+				"- .old: true\n",
+		),
+		Entry(
+			"Array items",
+			`{"items": [{"id": "a"}]}`,
+			`{"items": [{"id": "a"}, {"id": "b"}]}`,
+			"+ .items[1].id: \"b\"\n",
+		),
+		Entry(
+			"Emptied object",
+			`{"labels": {"x": "y"}}`,
+			`{"labels": {}}`,
+			"+ .labels: {}\n"+
+				"- .labels.x: \"y\"\n",
+		),
+		Entry(
+			"Large numbers",
+			`{"size": 12345678901234567890}`,
+			`{"size": 12345678901234567891}`,
+			"- .size: 12345678901234567890\n"+
+				"+ .size: 12345678901234567891\n",
+		),
+		Entry(
+			"Invalid document",
+			`{"id": "123"}`,
+			`not json`,
+			"not json\n",
+		),
+	)
+})
@@ -55,6 +55,34 @@ func Simple(stream io.Writer, body []byte) error {
 	return dumpJSON(stream, data)
 }
 
+// StripLinks removes the 'kind' and 'href' fields from all the objects contained in the given JSON
+// document, so that links to other objects are reduced to their identifiers. If the data isn't a
+// valid JSON document it is returned unchanged.
+func StripLinks(body []byte) ([]byte, error) {
+	var data interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return body, nil
+	}
+	stripLinks(data)
+	return json.Marshal(data)
+}
+
+func stripLinks(data interface{}) {
+	switch typed := data.(type) {
+	case map[string]interface{}:
+		delete(typed, "kind")
+		delete(typed, "href")
+		for _, value := range typed {
+			stripLinks(value)
+		}
+	case []interface{}:
+		for _, value := range typed {
+			stripLinks(value)
+		}
+	}
+}
+
 func dumpBytes(stream io.Writer, data []byte) error {
 	_, err := stream.Write(data)
 	if err != nil {
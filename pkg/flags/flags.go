@@ -27,7 +27,10 @@ import (
 	"github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/pflag"
 
+	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/debug"
+	"github.com/openshift-online/ocm-cli/pkg/operation"
+	"github.com/openshift-online/ocm-cli/pkg/output"
 )
 
 // AddDebugFlag adds the '--debug' flag to the given set of command line flags.
@@ -35,6 +38,36 @@ func AddDebugFlag(fs *pflag.FlagSet) {
 	debug.AddFlag(fs)
 }
 
+// AddRequestIDFlag adds the '--show-request-id' flag to the given set of command line flags.
+func AddRequestIDFlag(fs *pflag.FlagSet) {
+	operation.AddFlag(fs)
+}
+
+// AddProfileFlag adds the '--profile' flag to the given set of command line flags.
+func AddProfileFlag(fs *pflag.FlagSet) {
+	config.AddProfileFlag(fs)
+}
+
+// AddConfigFlag adds the '--config' flag to the given set of command line flags.
+func AddConfigFlag(fs *pflag.FlagSet) {
+	config.AddLocationFlag(fs)
+}
+
+// AddTimeoutFlag adds the '--timeout' flag to the given set of command line flags.
+func AddTimeoutFlag(fs *pflag.FlagSet) {
+	config.AddTimeoutFlag(fs)
+}
+
+// AddURLFlag adds the '--url' flag to the given set of command line flags.
+func AddURLFlag(fs *pflag.FlagSet) {
+	config.AddURLFlag(fs)
+}
+
+// AddOutputFlag adds the '--output' flag to the given set of command line flags.
+func AddOutputFlag(fs *pflag.FlagSet) {
+	output.AddFlag(fs)
+}
+
 // AddParameterFlag adds the '--parameter' flag to the given set of command line flags.
 func AddParameterFlag(fs *pflag.FlagSet, values *[]string) {
 	fs.StringArrayVar(
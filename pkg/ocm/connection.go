@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used by the commands to create connections to the API
+// gateway, so that the checks that are common to all of them are implemented only once.
+
+package ocm
+
+import (
+	"fmt"
+	"net/http"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+// NewConnection loads the configuration file, checks that it contains credentials or tokens that
+// haven't expired, and creates a connection. The caller is responsible for closing it.
+func NewConnection() (connection *sdk.Connection, err error) {
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return
+	}
+	connection, err = ConnectionFor(cfg)
+	return
+}
+
+// ConnectionFor does the same than NewConnection, but with a configuration that has already been
+// loaded or built, for the commands that need to use it for other purposes. The caller is
+// responsible for closing the connection.
+func ConnectionFor(cfg *config.Config) (connection *sdk.Connection, err error) {
+	// Check that the configuration has credentials or tokens that haven't expired:
+	armed, reason, err := cfg.Armed()
+	if err != nil {
+		err = fmt.Errorf("Can't check if tokens have expired: %v", err)
+		return
+	}
+	if !armed {
+		err = fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
+		return
+	}
+
+	// Check the rest of the configuration, as it may have been edited by the user:
+	err = cfg.Validate()
+	if err != nil {
+		err = fmt.Errorf("Configuration isn't valid, %v, run the 'login' command", err)
+		return
+	}
+
+	// Create the connection:
+	connection, err = cfg.Connection()
+	if err != nil {
+		err = fmt.Errorf("Can't create connection: %v", err)
+		return
+	}
+
+	// When the URL has been overridden the tokens may have been issued for a different gateway,
+	// so check that they are accepted before the command sends its own requests:
+	url := config.URLOverride()
+	if url != "" {
+		err = checkGateway(connection, url)
+		if err != nil {
+			config.Close(connection)
+			connection = nil
+			return
+		}
+	}
+	return
+}
+
+// checkGateway sends a request to the given gateway to check that it accepts the tokens of the
+// connection.
+func checkGateway(connection *sdk.Connection, url string) error {
+	ctx, cancel := config.RequestContext(connection)
+	defer cancel()
+	response, err := connection.Get().
+		Path("/api/accounts_mgmt/v1/current_account").
+		SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Can't send request to '%s': %v", url, err)
+	}
+	if response.Status() == http.StatusUnauthorized {
+		return fmt.Errorf(
+			"Token not valid for '%s', log in to that gateway to get a valid one",
+			url,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--show-request-id' command line option.
+
+package operation
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// Header is the name of the response header that contains the identifier that the server assigns
+// to each request.
+const Header = "X-Operation-ID"
+
+// AddFlag adds the show request identifier flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&enabled,
+		"show-request-id",
+		false,
+		"Print to the standard error stream the identifier that the server assigned to "+
+			"the requests sent by the 'get', 'post', 'patch' and 'delete' commands. This "+
+			"identifier is useful when contacting support.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if printing of request identifiers is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// Print writes the given request identifier to the standard error stream, but only if printing
+// of request identifiers is enabled and the identifier isn't empty.
+func Print(id string) {
+	if enabled && id != "" {
+		fmt.Fprintf(os.Stderr, "Request ID: %s\n", id)
+	}
+}
+
+// enabled is a boolean flag that indicates that printing of request identifiers is enabled.
+var enabled bool
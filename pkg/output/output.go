@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--output' command line option, which
+// selects the format used to report errors, and by some commands the format of their results.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Valid values of the '--output' command line option:
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatEnv  = "env"
+)
+
+// DefaultCode is the code used for errors that haven't been created with the Errorf function.
+const DefaultCode = "CLI-ERROR"
+
+// Error is an error that has a stable code, so that programs can check it without having to parse
+// the text of the reason.
+type Error struct {
+	Code   string
+	Reason string
+}
+
+// Errorf creates a new error with the given code and a reason formatted like fmt.Errorf does.
+func Errorf(code string, format string, args ...interface{}) error {
+	return &Error{
+		Code:   code,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error is the implementation of the error interface.
+func (e *Error) Error() string {
+	return e.Reason
+}
+
+// AddFlag adds the output flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&format,
+		"output",
+		FormatText,
+		fmt.Sprintf(
+			"Output format. Valid values are '%s', '%s' and '%s'. When it is '%s' "+
+				"errors are written to the standard output as JSON objects. The '%s' "+
+				"format prints shell variable assignments suitable for 'eval' or "+
+				"'source', and is only supported by some commands, like 'whoami' and "+
+				"'token'.",
+			FormatText, FormatJSON, FormatEnv, FormatJSON, FormatEnv,
+		),
+	)
+}
+
+// AddEnvFormat indicates that the given command supports the 'env' value of the '--output'
+// command line option.
+func AddEnvFormat(cmd *cobra.Command) {
+	envCommands[cmd] = true
+}
+
+// Check checks that the value given to the '--output' command line option is valid, and that it is
+// supported by the given command.
+func Check(cmd *cobra.Command) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	case FormatEnv:
+		if !envCommands[cmd] {
+			return fmt.Errorf(
+				"Output format '%s' isn't supported by the '%s' command",
+				format, cmd.CommandPath(),
+			)
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"Unknown output format '%s', valid values are '%s', '%s' and '%s'",
+			format, FormatText, FormatJSON, FormatEnv,
+		)
+	}
+}
+
+// Env returns a boolean flag indicating if the results of the command should be printed as shell
+// variable assignments.
+func Env() bool {
+	return format == FormatEnv
+}
+
+// JSON returns a boolean flag indicating if errors, and the results of the commands that support
+// it, should be printed in JSON format.
+func JSON() bool {
+	return format == FormatJSON
+}
+
+// PrintError writes the given error to the given stream as a JSON object.
+func PrintError(stream io.Writer, failure error) error {
+	code := DefaultCode
+	if typed, ok := failure.(*Error); ok {
+		code = typed.Code
+	}
+	body := struct {
+		Kind   string `json:"kind"`
+		Code   string `json:"code"`
+		Reason string `json:"reason"`
+	}{
+		Kind:   "Error",
+		Code:   code,
+		Reason: failure.Error(),
+	}
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stream, "%s\n", data)
+	return err
+}
+
+// format is the value of the '--output' command line option.
+var format string
+
+// envCommands contains the commands that support the 'env' format.
+var envCommands = map[*cobra.Command]bool{}
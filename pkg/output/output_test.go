@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func TestOutput(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Output")
+}
+
+var _ = Describe("Print error", func() {
+	DescribeTable(
+		"Errors",
+		func(err error, expected string) {
+			buffer := &bytes.Buffer{}
+			Expect(PrintError(buffer, err)).To(Succeed())
+			Expect(buffer.String()).To(MatchJSON(expected))
+		},
+		Entry(
+			"With code",
+			Errorf("MY-CODE", "My %s", "reason"),
+			`{"kind": "Error", "code": "MY-CODE", "reason": "My reason"}`,
+		),
+		Entry(
+			"Without code",
+			fmt.Errorf("My reason"),
+			`{"kind": "Error", "code": "CLI-ERROR", "reason": "My reason"}`,
+		),
+	)
+})
+
+var _ = Describe("Check", func() {
+	saved := format
+	supported := &cobra.Command{Use: "supported"}
+	unsupported := &cobra.Command{Use: "unsupported"}
+	AddEnvFormat(supported)
+
+	AfterEach(func() {
+		format = saved
+	})
+
+	DescribeTable(
+		"Valid",
+		func(value string, cmd *cobra.Command) {
+			format = value
+			Expect(Check(cmd)).To(Succeed())
+		},
+		Entry("Text", FormatText, unsupported),
+		Entry("JSON", FormatJSON, unsupported),
+		Entry("Env supported by the command", FormatEnv, supported),
+	)
+
+	DescribeTable(
+		"Invalid",
+		func(value string, cmd *cobra.Command, expected string) {
+			format = value
+			err := Check(cmd)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expected))
+		},
+		Entry(
+			"Env not supported by the command",
+			FormatEnv, unsupported,
+			"isn't supported by the 'unsupported' command",
+		),
+		Entry(
+			"Unknown",
+			"yaml", supported,
+			"Unknown output format 'yaml'",
+		),
+	)
+})
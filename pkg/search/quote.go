@@ -0,0 +1,30 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to build the search parameters of the API requests.
+
+package search
+
+import (
+	"strings"
+)
+
+// Quote returns the given value as a string literal that can be used in a search parameter,
+// surrounded by single quotes and with the single quotes that it contains doubled, so that values
+// given by the user can't change the meaning of the search.
+func Quote(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
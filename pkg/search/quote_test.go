@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestSearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Search")
+}
+
+var _ = Describe("Quote", func() {
+	DescribeTable(
+		"Values",
+		func(value string, expected string) {
+			Expect(Quote(value)).To(Equal(expected))
+		},
+		Entry("Empty", "", "''"),
+		Entry("Plain", "my-cluster", "'my-cluster'"),
+		Entry("Single quote", "my'cluster", "'my''cluster'"),
+		Entry("Injection", "x' or name like '%", "'x'' or name like ''%'"),
+	)
+})
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to generate shell code.
+
+package shell
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Quote returns the given value quoted so that it can be safely used as a single word in a POSIX
+// shell. The value is enclosed in single quotes, and single quotes inside the value are replaced
+// by a sequence that closes the quotes, adds an escaped quote, and opens them again.
+func Quote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// Assign writes to the given stream a shell variable assignment for the given name and value,
+// quoting the value as needed.
+func Assign(stream io.Writer, name string, value string) error {
+	_, err := fmt.Fprintf(stream, "%s=%s\n", name, Quote(value))
+	return err
+}
+
+// Shell formats supported by the Export function:
+const (
+	FormatSh   = "sh"
+	FormatFish = "fish"
+)
+
+// QuoteFish returns the given value quoted so that it can be safely used as a single word in the
+// fish shell. The value is enclosed in single quotes, and backslashes and single quotes inside the
+// value are escaped with a backslash.
+func QuoteFish(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "'", `\'`, -1)
+	return "'" + value + "'"
+}
+
+// Export writes to the given stream a statement that sets and exports the given environment
+// variable, using the syntax of the given shell format.
+func Export(stream io.Writer, format string, name string, value string) error {
+	var err error
+	switch format {
+	case FormatSh:
+		_, err = fmt.Fprintf(stream, "export %s=%s\n", name, Quote(value))
+	case FormatFish:
+		_, err = fmt.Fprintf(stream, "set -gx %s %s\n", name, QuoteFish(value))
+	default:
+		err = fmt.Errorf("unknown shell format '%s'", format)
+	}
+	return err
+}
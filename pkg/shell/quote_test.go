@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestShell(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shell")
+}
+
+var _ = Describe("Quote", func() {
+	DescribeTable(
+		"Values",
+		func(value string, expected string) {
+			Expect(Quote(value)).To(Equal(expected))
+		},
+		Entry("Empty", "", `''`),
+		Entry("Simple", "abc", `'abc'`),
+		Entry("Spaces", "a b", `'a b'`),
+		Entry("Dollar", "$HOME", `'$HOME'`),
+		Entry("Double quote", `a"b`, `'a"b'`),
+		Entry("Single quote", "a'b", `'a'\''b'`),
+	)
+})
+
+var _ = Describe("Quote fish", func() {
+	DescribeTable(
+		"Values",
+		func(value string, expected string) {
+			Expect(QuoteFish(value)).To(Equal(expected))
+		},
+		Entry("Empty", "", `''`),
+		Entry("Simple", "abc", `'abc'`),
+		Entry("Dollar", "$HOME", `'$HOME'`),
+		Entry("Single quote", "a'b", `'a\'b'`),
+		Entry("Backslash", `a\b`, `'a\\b'`),
+	)
+})
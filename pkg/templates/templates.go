@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the request body templates that are included in the binary, and the
+// functions used to fill them.
+
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template is a named request body with placeholders for the values given by the user.
+type Template struct {
+	// Name is the name used to select the template in the command line.
+	Name string
+
+	// Description explains what the template is for, including the path where it should be
+	// sent.
+	Description string
+
+	// Keys are the names of the values that need to be given to fill the template.
+	Keys []string
+
+	// Body is the text of the template. Values are inserted with the 'json' function, so that
+	// they are always quoted and escaped correctly.
+	Body string
+}
+
+// all contains the templates that are included in the binary, indexed by name.
+var all = map[string]*Template{
+	"cluster-minimal": {
+		Name: "cluster-minimal",
+		Description: "Minimal cluster, to be sent to '/api/clusters_mgmt/v1/clusters'. " +
+			"The rest of the settings take the default values.",
+		Keys: []string{"name", "region"},
+		Body: `{
+  "name": {{ json .name }},
+  "region": {
+    "id": {{ json .region }}
+  }
+}`,
+	},
+	"gitlab-idp": {
+		Name: "gitlab-idp",
+		Description: "GitLab identity provider, to be sent to " +
+			"'/api/clusters_mgmt/v1/clusters/{id}/identity_providers'.",
+		Keys: []string{"name", "url", "client_id", "client_secret"},
+		Body: `{
+  "type": "gitlab",
+  "name": {{ json .name }},
+  "mapping_method": "claim",
+  "gitlab": {
+    "url": {{ json .url }},
+    "client_id": {{ json .client_id }},
+    "client_secret": {{ json .client_secret }}
+  }
+}`,
+	},
+}
+
+// List returns the templates sorted by name.
+func List() []*Template {
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*Template, len(names))
+	for i, name := range names {
+		result[i] = all[name]
+	}
+	return result
+}
+
+// Lookup returns the template with the given name, or nil if there is no such template.
+func Lookup(name string) *Template {
+	return all[name]
+}
+
+// Fill replaces the placeholders of the template with the given values. It returns an error if
+// any of the keys of the template is missing or if there are values that the template doesn't
+// use.
+func (t *Template) Fill(values map[string]string) (result []byte, err error) {
+	var missing []string
+	for _, key := range t.Keys {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf(
+			"template '%s' requires values for %s",
+			t.Name, strings.Join(missing, ", "),
+		)
+		return
+	}
+	for key := range values {
+		if !t.hasKey(key) {
+			err = fmt.Errorf(
+				"template '%s' doesn't use value '%s', valid values are %s",
+				t.Name, key, strings.Join(t.Keys, ", "),
+			)
+			return
+		}
+	}
+	parsed, err := template.New(t.Name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"json": quote,
+		}).
+		Parse(t.Body)
+	if err != nil {
+		err = fmt.Errorf("can't parse template '%s': %v", t.Name, err)
+		return
+	}
+	buffer := new(bytes.Buffer)
+	err = parsed.Execute(buffer, values)
+	if err != nil {
+		err = fmt.Errorf("can't fill template '%s': %v", t.Name, err)
+		return
+	}
+	result = buffer.Bytes()
+	return
+}
+
+func (t *Template) hasKey(key string) bool {
+	for _, candidate := range t.Keys {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
+
+// quote converts the given value into a JSON string.
+func quote(value string) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
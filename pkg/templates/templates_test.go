@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Templates")
+}
+
+var _ = Describe("Fill", func() {
+	It("Generates valid JSON for all the templates", func() {
+		for _, tmpl := range List() {
+			values := map[string]string{}
+			for _, key := range tmpl.Keys {
+				values[key] = `my "value"`
+			}
+			result, err := tmpl.Fill(values)
+			Expect(err).ToNot(HaveOccurred())
+			var parsed map[string]interface{}
+			Expect(json.Unmarshal(result, &parsed)).To(Succeed())
+		}
+	})
+
+	It("Replaces the values", func() {
+		result, err := Lookup("cluster-minimal").Fill(map[string]string{
+			"name":   "mycluster",
+			"region": "us-east-1",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(MatchJSON(`{
+			"name": "mycluster",
+			"region": {
+				"id": "us-east-1"
+			}
+		}`))
+	})
+
+	It("Fails if a value is missing", func() {
+		_, err := Lookup("cluster-minimal").Fill(map[string]string{
+			"name": "mycluster",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("region"))
+	})
+
+	It("Fails if a value isn't used", func() {
+		_, err := Lookup("cluster-minimal").Fill(map[string]string{
+			"name":   "mycluster",
+			"region": "us-east-1",
+			"junk":   "junk",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("junk"))
+	})
+})
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to display times relative to the current time.
+
+package times
+
+import (
+	"fmt"
+	"time"
+)
+
+// relativeUnits are the units used to display relative times, from the largest to the smallest.
+var relativeUnits = []struct {
+	name   string
+	length time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+}
+
+// Relative returns a text describing the given time relative to the given current time, for
+// example '3 days ago' or 'in 2 hours'. Only the largest unit is used, and differences of less
+// than a minute are described as 'just now'.
+func Relative(value time.Time, now time.Time) string {
+	difference := now.Sub(value)
+	future := difference < 0
+	if future {
+		difference = -difference
+	}
+	for _, unit := range relativeUnits {
+		count := int64(difference / unit.length)
+		if count == 0 {
+			continue
+		}
+		text := fmt.Sprintf("%d %s", count, unit.name)
+		if count > 1 {
+			text += "s"
+		}
+		if future {
+			return "in " + text
+		}
+		return text + " ago"
+	}
+	return "just now"
+}
+
+// RelativeText is like Relative, but the time is given as text in RFC 3339 format. It returns the
+// text unchanged if it can't be parsed.
+func RelativeText(value string, now time.Time) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return Relative(parsed, now)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package times
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestTimes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Times")
+}
+
+var _ = Describe("Relative", func() {
+	now := time.Date(2019, time.November, 20, 12, 0, 0, 0, time.UTC)
+
+	DescribeTable(
+		"Times",
+		func(value time.Time, expected string) {
+			Expect(Relative(value, now)).To(Equal(expected))
+		},
+		Entry("Same time", now, "just now"),
+		Entry("Seconds ago", now.Add(-30*time.Second), "just now"),
+		Entry("One minute ago", now.Add(-time.Minute), "1 minute ago"),
+		Entry("Hours ago", now.Add(-5*time.Hour-10*time.Minute), "5 hours ago"),
+		Entry("Days ago", now.Add(-3*24*time.Hour), "3 days ago"),
+		Entry("Months ago", now.Add(-65*24*time.Hour), "2 months ago"),
+		Entry("Years ago", now.Add(-800*24*time.Hour), "2 years ago"),
+		Entry("In one hour", now.Add(time.Hour), "in 1 hour"),
+		Entry("In days", now.Add(50*time.Hour), "in 2 days"),
+	)
+})
+
+var _ = Describe("RelativeText", func() {
+	now := time.Date(2019, time.November, 20, 12, 0, 0, 0, time.UTC)
+
+	DescribeTable(
+		"Texts",
+		func(value string, expected string) {
+			Expect(RelativeText(value, now)).To(Equal(expected))
+		},
+		Entry("Valid", "2019-11-17T12:00:00Z", "3 days ago"),
+		Entry("Invalid", "NONE", "NONE"),
+	)
+})
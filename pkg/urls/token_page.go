@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package urls
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultTokenPage is the URL of the page where users of the production environment can obtain
+// their offline access token.
+const DefaultTokenPage = "https://cloud.redhat.com/openshift/token"
+
+// tokenPages maps the host names of the API gateways to the URLs of the pages where users can
+// obtain offline access tokens for them.
+var tokenPages = map[string]string{
+	"api.openshift.com":       DefaultTokenPage,
+	"api.stage.openshift.com": "https://qaprodauth.cloud.redhat.com/openshift/token",
+}
+
+// TokenPage returns the URL of the page where users can obtain the offline access token for the
+// API gateway with the given URL. If the gateway isn't known it returns the page of the production
+// environment.
+func TokenPage(gateway string) string {
+	parsed, err := url.Parse(gateway)
+	if err != nil {
+		return DefaultTokenPage
+	}
+	page, ok := tokenPages[strings.ToLower(parsed.Hostname())]
+	if !ok {
+		return DefaultTokenPage
+	}
+	return page
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package urls
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenPage", func() {
+	DescribeTable(
+		"Gateways",
+		func(gateway string, expected string) {
+			Expect(TokenPage(gateway)).To(Equal(expected))
+		},
+		Entry(
+			"Production",
+			"https://api.openshift.com",
+			"https://cloud.redhat.com/openshift/token",
+		),
+		Entry(
+			"Staging",
+			"https://api.stage.openshift.com",
+			"https://qaprodauth.cloud.redhat.com/openshift/token",
+		),
+		Entry(
+			"Host name is case insensitive",
+			"https://API.Stage.OpenShift.com/",
+			"https://qaprodauth.cloud.redhat.com/openshift/token",
+		),
+		Entry(
+			"Unknown gateway uses production",
+			"https://localhost:8000",
+			"https://cloud.redhat.com/openshift/token",
+		),
+		Entry(
+			"Invalid URL uses production",
+			"://",
+			"https://cloud.redhat.com/openshift/token",
+		),
+	)
+})